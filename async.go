@@ -2,7 +2,13 @@ package meteor
 
 //go:generate moq -out async_mocks_test.go . AsyncDoer
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
 
 // AsyncDoer does the work for an async job/task
 // for use by async
@@ -21,22 +27,77 @@ type AsyncDoer interface {
 	ToStop() string
 }
 
+// AsyncContextDoer is an AsyncDoer that can bind its work to the
+// context of the async run. When the context is cancelled, the work
+// should stop as soon as possible.
+type AsyncContextDoer interface {
+	AsyncDoer
+
+	// PrepareContext is like Prepare but does the work with ctx.
+	PrepareContext(context.Context, int)
+}
+
 // NewAsyncDoers is a nice wrapper for creating AsyncDoer slice.
 func NewAsyncDoers(doers ...AsyncDoer) []AsyncDoer {
 	return doers
 }
 
+// asyncStop is a request to stop the async.
+type asyncStop struct {
+	reason  StopReason
+	message string
+}
+
+// asyncResult is the value of the AsyncDoer at index.
+type asyncResult struct {
+	index int
+	value interface{}
+}
+
 // async
 type async struct {
-	service   *Service
-	toDos     []AsyncDoer
-	responses []interface{}
-	ch        chan interface{}
-	stopCh    chan struct{}
-	toStop    chan string
-	closed    bool
-	StoppedBy string
-	length    int
+	ctx        context.Context
+	service    *Service
+	toDos      []AsyncDoer
+	workers    int
+	timeout    time.Duration
+	completion Completion
+	mu         sync.Mutex
+	cancels    map[int]context.CancelFunc
+	results    []asyncResult
+	responses  []interface{}
+	ch         chan asyncResult
+	stopCh     chan struct{}
+	toStop     chan asyncStop
+	closed     bool
+	// StoppedBy is the reason the async stopped.
+	StoppedBy StopReason
+	// StopMessage is the string returned by the AsyncDoer's ToStop
+	// when StoppedBy is StoppedByDoer.
+	StopMessage string
+	length      int
+}
+
+// Workers limits the number of toDos done at once to n workers.
+// A value of 0 or less starts one goroutine per toDo.
+func (a *async) Workers(n int) *async {
+	a.workers = n
+	return a
+}
+
+// Timeout bounds the time each toDo may take. The timeout applies to
+// AsyncContextDoers, whose work is done with a context that expires;
+// plain AsyncDoers have no context and are not bounded.
+func (a *async) Timeout(d time.Duration) *async {
+	a.timeout = d
+	return a
+}
+
+// Completion sets when the async is complete. Unfinished toDos are
+// cancelled through their context.
+func (a *async) Completion(c Completion) *async {
+	a.completion = c
+	return a
 }
 
 // IsClosed tells you whether the channel has been closed or not.
@@ -45,33 +106,105 @@ func (a *async) IsClosed() bool {
 }
 
 // Moderator moderates stopping to ensure async channel(s) close.
+// Unfinished toDos are cancelled once the async stops.
 func (a *async) Moderator() {
-	a.StoppedBy = <-a.toStop
-	close(a.stopCh)
+	stop := <-a.toStop
+	a.StoppedBy, a.StopMessage = stop.reason, stop.message
+	a.cancelPending()
 	a.closed = true
+	close(a.stopCh)
 }
 
 // Restart restarts the toStop channel.
 func (a *async) Restart() {
 	a.closed = false
-	a.toStop = make(chan string, 1)
+	a.toStop = make(chan asyncStop, 1)
 	go a.Moderator()
 }
 
+// stop asks the Moderator to stop the async and waits until it has.
+func (a *async) stop(reason StopReason, message string) {
+	select {
+	case a.toStop <- asyncStop{reason, message}:
+	default:
+	}
+	<-a.stopCh
+}
+
+// track records the cancel func of the toDo at index, so it can be
+// cancelled if the async stops before the toDo is done. It returns
+// false if the async has already stopped.
+func (a *async) track(index int, cancel context.CancelFunc) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cancels == nil {
+		return false
+	}
+	a.cancels[index] = cancel
+	return true
+}
+
+// untrack forgets the toDo at index once it is done.
+func (a *async) untrack(index int) {
+	a.mu.Lock()
+	delete(a.cancels, index)
+	a.mu.Unlock()
+}
+
+// cancelPending cancels the context of every unfinished toDo.
+func (a *async) cancelPending() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, cancel := range a.cancels {
+		cancel()
+	}
+	a.cancels = nil
+}
+
 // do does the work of the job/task by calling the AsyncDoer methods
 // gracefully closing channels.
 func (a *async) do(index int, item AsyncDoer) {
 	select {
 	case <-a.stopCh:
+		discard(item)
+		return
+	case <-a.ctx.Done():
+		discard(item)
 		return
 	default:
 	}
 
-	item.Prepare(index)
+	// release cancels the context of the toDo once it is done; Service.Do
+	// has read and closed the response body by then.
+	release := func() {}
+	if cd, ok := item.(AsyncContextDoer); ok {
+		ctx, cancel := context.WithCancel(a.ctx)
+		if !a.track(index, cancel) {
+			cancel()
+			discard(item)
+			return
+		}
+		cancelTimeout := func() {}
+		if a.timeout > 0 {
+			ctx, cancelTimeout = context.WithTimeout(ctx, a.timeout)
+		}
+		release = func() {
+			a.untrack(index)
+			cancelTimeout()
+			cancel()
+		}
+		cd.PrepareContext(ctx, index)
+	} else {
+		item.Prepare(index)
+	}
 	value := item.Do()
+	release()
+	if ar, ok := value.(*AsyncResponse); ok {
+		ar.Index = index
+	}
 	if toStop := item.ToStop(); toStop != "" {
 		select {
-		case a.toStop <- toStop:
+		case a.toStop <- asyncStop{StoppedByDoer, toStop}:
 		default:
 		}
 		return
@@ -80,34 +213,127 @@ func (a *async) do(index int, item AsyncDoer) {
 	select {
 	case <-a.stopCh:
 		return
-	case a.ch <- value:
+	case a.ch <- asyncResult{index, value}:
+	}
+}
+
+// discard closes the request bodies of toDos that are not done.
+func discard(items ...AsyncDoer) {
+	for _, item := range items {
+		if ar, ok := item.(*AsyncRequest); ok && ar.Request != nil {
+			closeRequestBody(ar.Request)
+		}
+	}
+}
+
+// start starts the toDos, either one goroutine per toDo or
+// a pool of workers.
+func (a *async) start() {
+	if a.workers <= 0 || a.workers >= len(a.toDos) {
+		for i, item := range a.toDos {
+			go a.do(i, item)
+		}
+		return
+	}
+
+	jobs := make(chan int)
+	for w := 0; w < a.workers; w++ {
+		go func() {
+			for i := range jobs {
+				a.do(i, a.toDos[i])
+			}
+		}()
 	}
+	go func() {
+		defer close(jobs)
+		for i := range a.toDos {
+			select {
+			case <-a.stopCh:
+				discard(a.toDos[i:]...)
+				return
+			case <-a.ctx.Done():
+				discard(a.toDos[i:]...)
+				return
+			case jobs <- i:
+			}
+		}
+	}()
 }
 
-// Do performs the toDos asyncronously.
+// Do performs the toDos asyncronously and returns their values
+// in the order of the toDos.
 func (a *async) Do() []interface{} {
-	for i, item := range a.toDos {
-		go a.do(i, item)
+	a.start()
+	a.collect(func(r asyncResult) {
+		a.results = append(a.results, r)
+	})
+
+	sort.SliceStable(a.results, func(i, j int) bool {
+		return a.results[i].index < a.results[j].index
+	})
+	for _, r := range a.results {
+		a.responses = append(a.responses, r.value)
 	}
+	return a.responses
+}
 
+// Stream performs the toDos asyncronously and sends their values
+// as soon as they are done. The channel is closed once the async
+// stops or its context is done.
+func (a *async) Stream() <-chan interface{} {
+	out := make(chan interface{})
+	a.start()
+	go func() {
+		defer close(out)
+		a.collect(func(r asyncResult) {
+			select {
+			case out <- r.value:
+			case <-a.ctx.Done():
+			}
+		})
+	}()
+	return out
+}
+
+// collect passes the values of the toDos to fn until the async stops
+// or is complete.
+func (a *async) collect(fn func(asyncResult)) {
+	var received, succeeded int
+	if a.completion.unreachable(0, a.length) {
+		a.stop(StoppedNoQuorum, "")
+		return
+	}
 	for {
 		select {
 		case <-a.stopCh:
-			return a.responses
+			return
 		default:
 		}
 
 		select {
 		case <-a.stopCh:
-			return a.responses
+			return
+		case <-a.ctx.Done():
+			a.stop(StoppedCancelled, a.ctx.Err().Error())
+			return
 		case r := <-a.ch:
-			a.responses = append(a.responses, r)
-			if len(a.responses) == a.length {
-				select {
-				case a.toStop <- "asyncAutoStopped":
-				default:
-				}
-				return a.responses
+			fn(r)
+			received++
+			failed := asyncFailed(r.value)
+			if !failed {
+				succeeded++
+			}
+			if a.completion.done(succeeded, failed) {
+				a.stop(a.completion.reason, "")
+				return
+			}
+			if a.completion.unreachable(succeeded, a.length-received) {
+				a.stop(StoppedNoQuorum, "")
+				return
+			}
+			if received == a.length {
+				a.stop(StoppedAllDone, "")
+				return
 			}
 		}
 	}
@@ -118,8 +344,15 @@ func (a *async) GetResponses() []interface{} {
 	return a.responses
 }
 
-// NewAsync creates a new async service.
+// NewAsync creates a new async service using the service's context.
 func NewAsync(service *Service, toDos []AsyncDoer, length ...int) *async {
+	return NewAsyncContext(service.GetContext(), service, toDos, length...)
+}
+
+// NewAsyncContext creates a new async service. Cancelling ctx stops
+// every pending job. The service's AsyncWorkers, AsyncTimeout and
+// AsyncCompletion are used.
+func NewAsyncContext(ctx context.Context, service *Service, toDos []AsyncDoer, length ...int) *async {
 	var l int
 	if len(length) == 0 || (len(length) == 1 && length[0] == 0) {
 		l = len(toDos)
@@ -128,15 +361,23 @@ func NewAsync(service *Service, toDos []AsyncDoer, length ...int) *async {
 	}
 
 	a := &async{
+		ctx:       ctx,
 		service:   service,
 		toDos:     toDos,
+		results:   make([]asyncResult, 0, l),
 		responses: make([]interface{}, 0),
-		ch:        make(chan interface{}, l),
+		ch:        make(chan asyncResult, l),
 		stopCh:    make(chan struct{}),
-		toStop:    make(chan string, 1),
+		cancels:   make(map[int]context.CancelFunc),
+		toStop:    make(chan asyncStop, 1),
 		closed:    false,
 		length:    l,
 	}
+	if service != nil {
+		a.workers = service.asyncWorkers
+		a.timeout = service.asyncTimeout
+		a.completion = service.asyncCompletion
+	}
 	go a.Moderator()
 	return a
 }
@@ -155,7 +396,7 @@ type AsyncRequest struct {
 func NewAsyncRequest(service *Service) *AsyncRequest {
 	req, err := service.Request()
 	return &AsyncRequest{
-		responder: service.GetResponder(),
+		responder: service.callResponder(),
 		Request:   req,
 		Error:     err,
 		service:   service,
@@ -195,17 +436,42 @@ func (ar *AsyncRequest) GetError() error {
 	return ar.Error
 }
 
-// Prepare prepares the AsyncRequest object to do the work.
+// Prepare prepares the AsyncRequest object to do the work
+// with the context of its request.
 // Implements asyncDoer
 func (ar *AsyncRequest) Prepare(index int) {
-	resp, err := ar.service.Responder(ar.responder).Do(ar.Request)
+	ctx := ar.service.GetContext()
+	if ar.Request != nil {
+		ctx = ar.Request.Context()
+	}
+	ar.PrepareContext(ctx, index)
+}
+
+// PrepareContext prepares the AsyncRequest object to do the work
+// with the context of the async run. The work is done with the
+// AsyncRequest's own responder, leaving the service untouched.
+// Implements AsyncContextDoer
+func (ar *AsyncRequest) PrepareContext(ctx context.Context, index int) {
+	start := time.Now()
+	resp, err := ar.do(ctx)
 	ar.response = &AsyncResponse{
-		responder: ar.service.responder,
+		responder: ar.responder,
+		Index:     index,
 		Response:  resp,
 		Error:     err,
+		Duration:  time.Since(start),
 	}
 }
 
+// do sends the request with ctx, creating it from the service if needed.
+func (ar *AsyncRequest) do(ctx context.Context) (*http.Response, error) {
+	req, err := ar.service.contextRequest(ctx, ar.Request)
+	if err != nil {
+		return nil, err
+	}
+	return ar.service.do(req, ar.responder)
+}
+
 // Do does the work of the AsyncRequest.
 // Implements asyncDoer
 func (ar *AsyncRequest) Do() interface{} {
@@ -223,8 +489,12 @@ func (ar *AsyncRequest) ToStop() string {
 // Implements Response interface.
 type AsyncResponse struct {
 	responder Responder
-	Response  *http.Response
-	Error     error
+	// Index is the index of the AsyncDoer that produced the response.
+	Index    int
+	Response *http.Response
+	Error    error
+	// Duration is the time taken to send the request and handle its response.
+	Duration time.Duration
 }
 
 // GetRequest gets the request.
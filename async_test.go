@@ -0,0 +1,331 @@
+package meteor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingDoer records the maximum number of doers running at once.
+type countingDoer struct {
+	mu      *sync.Mutex
+	running *int
+	max     *int
+	index   int
+}
+
+func (d *countingDoer) Prepare(index int) {
+	d.mu.Lock()
+	*d.running++
+	if *d.running > *d.max {
+		*d.max = *d.running
+	}
+	d.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+	d.index = index
+
+	d.mu.Lock()
+	*d.running--
+	d.mu.Unlock()
+}
+
+func (d *countingDoer) Do() interface{} {
+	return d.index
+}
+
+func (d *countingDoer) ToStop() string {
+	return ""
+}
+
+func Test_async_Workers(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		toDos   int
+		wantMax int
+	}{
+		{"pool", 3, 12, 3},
+		{"single", 1, 5, 1},
+		{"moreWorkers", 20, 4, 4},
+		{"unbounded", 0, 8, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var running, max int
+			toDos := make([]AsyncDoer, tt.toDos)
+			for i := range toDos {
+				toDos[i] = &countingDoer{mu: &mu, running: &running, max: &max}
+			}
+
+			got := NewAsyncContext(context.Background(), nil, toDos).Workers(tt.workers).Do()
+			if len(got) != tt.toDos {
+				t.Fatalf("%v async.Do() len = %v, want %v", tt.name, len(got), tt.toDos)
+			}
+			for i, v := range got {
+				if v.(int) != i {
+					t.Errorf("%v async.Do()[%v] = %v, want %v", tt.name, i, v, i)
+				}
+			}
+			if max > tt.wantMax {
+				t.Errorf("%v async.Do() ran %v at once, want at most %v", tt.name, max, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestService_AsyncWorkers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		// later requests answer first
+		time.Sleep(time.Duration(10-n) * time.Millisecond)
+		w.Write([]byte(`{"text": "` + r.URL.Query().Get("n") + `"}`))
+	}))
+	defer server.Close()
+
+	svc := New().Base(server.URL).AsyncWorkers(3).ResponderFunc(func() Responder {
+		return JSONSuccessResponder(&FakeModel{})
+	})
+	var reqs []AsyncDoer
+	for i := 0; i < 10; i++ {
+		params := &struct {
+			N int `url:"n"`
+		}{i}
+		reqs = append(reqs, svc.New().QueryStruct(params).AsyncRequest(nil))
+	}
+
+	got := svc.DoAsync(reqs)
+	if len(got) != len(reqs) {
+		t.Fatalf("Service.DoAsync() len = %v, want %v", len(got), len(reqs))
+	}
+	for i, r := range got {
+		if r.GetError() != nil {
+			t.Errorf("Service.DoAsync()[%v] error = %v", i, r.GetError())
+			continue
+		}
+		if r.Index != i {
+			t.Errorf("Service.DoAsync()[%v] Index = %v", i, r.Index)
+		}
+		if text := r.GetSuccess().(*FakeModel).Text; text != strconv.Itoa(i) {
+			t.Errorf("Service.DoAsync()[%v] success = %v", i, text)
+		}
+	}
+}
+
+// contextDoer records the context it is prepared with.
+type contextDoer struct {
+	ctx context.Context
+}
+
+func (d *contextDoer) Prepare(index int) {}
+
+func (d *contextDoer) PrepareContext(ctx context.Context, index int) {
+	d.ctx = ctx
+}
+
+func (d *contextDoer) Do() interface{} {
+	return d.ctx.Err()
+}
+
+func (d *contextDoer) ToStop() string {
+	return ""
+}
+
+func Test_async_releasesContexts(t *testing.T) {
+	for _, timeout := range []time.Duration{0, time.Minute} {
+		doers := []*contextDoer{{}, {}}
+		got := NewAsync(New().AsyncTimeout(timeout), NewAsyncDoers(doers[0], doers[1])).Do()
+		for i, d := range doers {
+			if got[i] != nil {
+				t.Errorf("async.Do() timeout %v context error = %v, want nil", timeout, got[i])
+			}
+			if err := d.ctx.Err(); !errors.Is(err, context.Canceled) {
+				t.Errorf("async.Do() timeout %v context error after Do = %v, want %v", timeout, err, context.Canceled)
+			}
+		}
+	}
+}
+
+func TestService_AsyncTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		w.Write([]byte(`{"text": "fast"}`))
+	}))
+	defer server.Close()
+
+	svc := New().Base(server.URL).AsyncTimeout(50 * time.Millisecond)
+	reqs := NewAsyncDoers(
+		svc.New().Path("fast").AsyncRequest(JSONSuccessResponder(&FakeModel{})),
+		svc.New().Path("slow").AsyncRequest(JSONSuccessResponder(&FakeModel{})),
+	)
+
+	got := svc.DoAsync(reqs)
+	if len(got) != 2 {
+		t.Fatalf("Service.DoAsync() len = %v, want 2", len(got))
+	}
+	if err := got[0].GetError(); err != nil {
+		t.Errorf("Service.DoAsync() fast error = %v", err)
+	}
+	if err := got[1].GetError(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Service.DoAsync() slow error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestService_DoAsyncStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		// later requests answer first
+		time.Sleep(time.Duration(5-n) * 20 * time.Millisecond)
+		w.Write([]byte(`{"text": "` + r.URL.Query().Get("n") + `"}`))
+	}))
+	defer server.Close()
+
+	svc := New().Base(server.URL).ResponderFunc(func() Responder {
+		return JSONSuccessResponder(&FakeModel{})
+	})
+	var reqs []AsyncDoer
+	for i := 0; i < 5; i++ {
+		params := &struct {
+			N int `url:"n"`
+		}{i}
+		reqs = append(reqs, svc.New().QueryStruct(params).AsyncRequest(nil))
+	}
+
+	var order []int
+	for r := range svc.DoAsyncStream(context.Background(), reqs) {
+		if r.GetError() != nil {
+			t.Errorf("Service.DoAsyncStream() error = %v", r.GetError())
+			continue
+		}
+		if text := r.GetSuccess().(*FakeModel).Text; text != strconv.Itoa(r.Index) {
+			t.Errorf("Service.DoAsyncStream()[%v] success = %v", r.Index, text)
+		}
+		if r.Duration <= 0 {
+			t.Errorf("Service.DoAsyncStream()[%v] Duration = %v", r.Index, r.Duration)
+		}
+		order = append(order, r.Index)
+	}
+	if want := []int{4, 3, 2, 1, 0}; !assert.Equal(t, want, order) {
+		t.Errorf("Service.DoAsyncStream() order = %v, want %v", order, want)
+	}
+}
+
+func TestService_DoAsyncStream_cancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		w.Write([]byte(`{"text": "fast"}`))
+	}))
+	defer server.Close()
+
+	svc := New().Base(server.URL)
+	reqs := NewAsyncDoers(
+		svc.New().Path("fast").AsyncRequest(JSONSuccessResponder(&FakeModel{})),
+		svc.New().Path("slow").AsyncRequest(JSONSuccessResponder(&FakeModel{})),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := svc.DoAsyncStream(ctx, reqs)
+	if r := <-stream; r == nil || r.Index != 0 {
+		t.Fatalf("Service.DoAsyncStream() first = %v, want the fast response", r)
+	}
+	cancel()
+
+	select {
+	case _, ok := <-stream:
+		if ok {
+			// the slow response may have raced the cancellation
+			if _, ok = <-stream; ok {
+				t.Errorf("Service.DoAsyncStream() was not closed")
+			}
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Errorf("Service.DoAsyncStream() was not closed after cancel")
+	}
+}
+
+func TestService_AsyncCompletion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d, _ := strconv.Atoi(r.URL.Query().Get("d"))
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(time.Duration(d) * time.Millisecond):
+		}
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		w.Write([]byte(`{"text": "done"}`))
+	}))
+	defer server.Close()
+
+	svc := New().Base(server.URL).ResponderFunc(func() Responder {
+		return JSONSuccessResponder(&FakeModel{})
+	})
+	req := func(path string, d int) AsyncDoer {
+		params := &struct {
+			D int `url:"d"`
+		}{d}
+		return svc.New().Path(path).QueryStruct(params).AsyncRequest(nil)
+	}
+	tests := []struct {
+		name       string
+		completion Completion
+		timeout    time.Duration
+		reqs       []AsyncDoer
+		wantLen    int
+		wantReason StopReason
+	}{
+		{"waitAll", WaitAll(), 0, NewAsyncDoers(req("fail", 0), req("ok", 10), req("ok", 30)), 3, StoppedAllDone},
+		{"firstSuccess", FirstSuccess(), 0, NewAsyncDoers(req("fail", 0), req("ok", 50), req("ok", 5000)), 2, StoppedFirstSuccess},
+		{"firstSuccessNone", FirstSuccess(), 0, NewAsyncDoers(req("fail", 0), req("fail", 10)), 2, StoppedAllDone},
+		{"firstError", FirstError(), 0, NewAsyncDoers(req("ok", 0), req("fail", 50), req("ok", 5000)), 2, StoppedFirstError},
+		{"quorum", Quorum(2), 0, NewAsyncDoers(req("ok", 0), req("ok", 20), req("ok", 5000), req("ok", 5000)), 2, StoppedQuorum},
+		{"quorumZero", Quorum(0), 0, NewAsyncDoers(req("fail", 0), req("ok", 50), req("ok", 5000)), 2, StoppedQuorum},
+		{"quorumFailed", Quorum(2), 0, NewAsyncDoers(req("fail", 0), req("fail", 20), req("ok", 5000)), 2, StoppedNoQuorum},
+		{"quorumTooLarge", Quorum(3), 0, NewAsyncDoers(req("ok", 0), req("ok", 10)), 0, StoppedNoQuorum},
+		{"cancelled", WaitAll(), 50 * time.Millisecond, NewAsyncDoers(req("ok", 0), req("ok", 5000)), 1, StoppedCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			start := time.Now()
+			got, reason := svc.New().AsyncCompletion(tt.completion).DoAsyncBatch(ctx, tt.reqs)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("%v Service.DoAsyncBatch() took %v, unfinished requests were not cancelled", tt.name, elapsed)
+			}
+			if len(got) != tt.wantLen {
+				t.Errorf("%v Service.DoAsyncBatch() len = %v, want %v", tt.name, len(got), tt.wantLen)
+			}
+			if reason != tt.wantReason {
+				t.Errorf("%v Service.DoAsyncBatch() reason = %v, want %v", tt.name, reason, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package meteor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const (
+	multipartContentType   = "multipart/form-data"
+	octetStreamContentType = "application/octet-stream"
+)
+
+// MultipartFile is a file part of a MultipartBody. Its content is read from
+// Reader or, if Reader is nil, from the file at Path. Filename defaults to
+// the base of Path, and ContentType to the type of the Filename extension.
+type MultipartFile struct {
+	Filename    string
+	ContentType string
+	Reader      io.Reader
+	Path        string
+}
+
+// multipartPart is a field or file part of a MultipartBody.
+type multipartPart struct {
+	name  string
+	value string
+	file  *MultipartFile
+}
+
+// MultipartBody provides a multipart/form-data Body for requests. Parts are
+// added with Field, File, FilePath or from a multipart tagged struct with
+// Struct, and are streamed through an io.Pipe, so files are not buffered in
+// memory.
+//
+// Files are opened from their Path on every Body() call, so requests with
+// them can be resent, e.g. by retries. File parts read from a Reader can
+// only be resent if the Reader implements io.Seeker: once such a body was
+// created, Body() returns an error.
+//
+// The parts are written by a goroutine until the body is read to its end or
+// closed, so callers of Body(), or of Service.Request(), must close the body
+// of requests they do not send.
+type MultipartBody struct {
+	boundary string
+	parts    []multipartPart
+	err      error
+	mu       sync.Mutex
+	consumed bool
+	// reader and written are the pipe and the writer goroutine of the
+	// last body, which must be done before the parts are read again
+	reader  *io.PipeReader
+	written chan struct{}
+}
+
+// NewMultipartBody returns an empty MultipartBody with a random boundary.
+func NewMultipartBody() *MultipartBody {
+	return &MultipartBody{boundary: multipart.NewWriter(ioutil.Discard).Boundary()}
+}
+
+// Field adds a form field part.
+func (b *MultipartBody) Field(name, value string) *MultipartBody {
+	b.parts = append(b.parts, multipartPart{name: name, value: value})
+	return b
+}
+
+// File adds a file part read from r, with the filename and content type.
+// An empty contentType is guessed from the filename extension.
+func (b *MultipartBody) File(name, filename, contentType string, r io.Reader) *MultipartBody {
+	return b.add(name, &MultipartFile{Filename: filename, ContentType: contentType, Reader: r})
+}
+
+// FilePath adds a file part read from the file at path, opened when the
+// body is sent. The content type is guessed from the path extension unless
+// given.
+func (b *MultipartBody) FilePath(name, path string, contentType ...string) *MultipartBody {
+	file := &MultipartFile{Path: path}
+	if len(contentType) > 0 {
+		file.ContentType = contentType[0]
+	}
+	return b.add(name, file)
+}
+
+// add adds a file part.
+func (b *MultipartBody) add(name string, file *MultipartFile) *MultipartBody {
+	b.parts = append(b.parts, multipartPart{name: name, file: file})
+	return b
+}
+
+// Struct adds a part for each exported field of the struct, or pointer to a
+// struct, v. The part is named by the field's multipart tag, e.g.
+// `multipart:"media,omitempty"`, or by the field name, and fields tagged "-"
+// are skipped. MultipartFile and io.Reader fields are added as file parts,
+// slices as repeated fields, and other values are formatted with fmt.
+func (b *MultipartBody) Struct(v interface{}) *MultipartBody {
+	sv := reflect.ValueOf(v)
+	for sv.Kind() == reflect.Ptr && !sv.IsNil() {
+		sv = sv.Elem()
+	}
+	if sv.Kind() != reflect.Struct {
+		b.err = errors.Join(b.err, fmt.Errorf("meteor: multipart: expects a struct, got %T", v))
+		return b
+	}
+
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("multipart")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		fv := sv.Field(i)
+		if opts == "omitempty" && fv.IsZero() {
+			continue
+		}
+		b.addValue(name, fv)
+	}
+	return b
+}
+
+// addValue adds the parts of a struct field value. io.Reader files are named
+// after the field, or after the base of their name if they have a Name method,
+// such as *os.File.
+func (b *MultipartBody) addValue(name string, v reflect.Value) {
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return
+	}
+	switch value := v.Interface().(type) {
+	case MultipartFile:
+		b.add(name, &value)
+		return
+	case *MultipartFile:
+		b.add(name, value)
+		return
+	case io.Reader:
+		filename := name
+		if named, ok := value.(interface{ Name() string }); ok {
+			filename = filepath.Base(named.Name())
+		}
+		b.File(name, filename, "", value)
+		return
+	case []byte:
+		b.Field(name, string(value))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			b.addValue(name, v.Index(i))
+		}
+	case reflect.Ptr, reflect.Interface:
+		b.addValue(name, v.Elem())
+	default:
+		b.Field(name, fmt.Sprint(v.Interface()))
+	}
+}
+
+// ContentType gets the content type of the body, with its boundary.
+// Implements BodyProvider interface
+func (b *MultipartBody) ContentType() string {
+	return mime.FormatMediaType(multipartContentType, map[string]string{"boundary": b.boundary})
+}
+
+// Body returns the reading end of a pipe the parts are written to as it is
+// read. Closing the reader stops the writing. Body returns an error if a
+// part read from a Reader that is not an io.Seeker was already sent. A new
+// body closes the previous one and waits for its writing to stop before
+// rewinding the parts.
+// Implements BodyProvider interface
+func (b *MultipartBody) Body() (io.Reader, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.consumed {
+		return nil, errors.New("meteor: multipart: a part read from a Reader that is not an io.Seeker was already sent")
+	}
+	if b.reader != nil {
+		b.reader.Close()
+		<-b.written
+	}
+	for _, part := range b.parts {
+		if seeker, ok := partReader(part).(io.Seeker); ok {
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
+	}
+	b.consumed = !b.rewindable()
+
+	pr, pw := io.Pipe()
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		pw.CloseWithError(b.write(pw))
+	}()
+	b.reader, b.written = pr, written
+	return pr, nil
+}
+
+// Rewindable reports whether Body can be called again, i.e. whether every
+// file part is read from its Path or from an io.Seeker.
+// Implements RewindableBodyProvider interface
+func (b *MultipartBody) Rewindable() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.consumed && b.rewindable()
+}
+
+// rewindable reports whether every part can be read again.
+func (b *MultipartBody) rewindable() bool {
+	for _, part := range b.parts {
+		if r := partReader(part); r != nil {
+			if _, ok := r.(io.Seeker); !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// partReader gets the Reader of a file part, if any.
+func partReader(part multipartPart) io.Reader {
+	if part.file == nil {
+		return nil
+	}
+	return part.file.Reader
+}
+
+// write writes the parts to w.
+func (b *MultipartBody) write(w io.Writer) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(b.boundary); err != nil {
+		return err
+	}
+	for _, part := range b.parts {
+		if part.file == nil {
+			if err := mw.WriteField(part.name, part.value); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := writeMultipartFile(mw, part.name, part.file); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+// writeMultipartFile writes a file part, opening the file at its Path if it
+// has no Reader.
+func writeMultipartFile(mw *multipart.Writer, name string, file *MultipartFile) error {
+	r, filename := file.Reader, file.Filename
+	if r == nil {
+		f, err := os.Open(file.Path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+		if filename == "" {
+			filename = filepath.Base(file.Path)
+		}
+	}
+
+	ct := file.ContentType
+	if ct == "" {
+		ct = mime.TypeByExtension(filepath.Ext(filename))
+	}
+	if ct == "" {
+		ct = octetStreamContentType
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{"name": name, "filename": filename}))
+	header.Set(contentType, ct)
+	pw, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(pw, r)
+	return err
+}
@@ -0,0 +1,251 @@
+package meteor
+
+import (
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultipartBody_ContentType(t *testing.T) {
+	b := NewMultipartBody()
+	mediaType, params, err := mime.ParseMediaType(b.ContentType())
+	if err != nil {
+		t.Fatalf("MultipartBody.ContentType() error = %v", err)
+	}
+	assert.Equal(t, multipartContentType, mediaType)
+	assert.Equal(t, b.boundary, params["boundary"])
+}
+
+func TestMultipartBody_Body(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "forecast.json")
+	if err := ioutil.WriteFile(path, []byte(`{"text": "sunny"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	type UploadParams struct {
+		Status  string         `multipart:"status"`
+		Tags    []string       `multipart:"tag"`
+		Skipped string         `multipart:"-"`
+		Empty   string         `multipart:"empty,omitempty"`
+		Media   *MultipartFile `multipart:"media"`
+		Notes   *os.File       `multipart:"notes,omitempty"`
+	}
+	type part struct {
+		name, filename, contentType, content string
+	}
+	tests := []struct {
+		name    string
+		body    *MultipartBody
+		want    []part
+		wantErr bool
+	}{
+		{"fields", NewMultipartBody().Field("foo", "bar").Field("foo", "baz"), []part{
+			{"foo", "", "", "bar"},
+			{"foo", "", "", "baz"},
+		}, false},
+		{"file", NewMultipartBody().File("image", "map.png", "", strings.NewReader("png")), []part{
+			{"image", "map.png", "image/png", "png"},
+		}, false},
+		{"filePath", NewMultipartBody().FilePath("data", path), []part{
+			{"data", "forecast.json", "application/json", `{"text": "sunny"}`},
+		}, false},
+		{"filePathContentType", NewMultipartBody().FilePath("data", path, textContentType), []part{
+			{"data", "forecast.json", textContentType, `{"text": "sunny"}`},
+		}, false},
+		{"unknownType", NewMultipartBody().File("blob", "blob", "", strings.NewReader("raw")), []part{
+			{"blob", "blob", octetStreamContentType, "raw"},
+		}, false},
+		{"struct", NewMultipartBody().Struct(&UploadParams{
+			Status:  "uploading",
+			Tags:    []string{"a", "b"},
+			Skipped: "skipped",
+			Media:   &MultipartFile{Filename: "radar.gif", Reader: strings.NewReader("gif")},
+		}), []part{
+			{"status", "", "", "uploading"},
+			{"tag", "", "", "a"},
+			{"tag", "", "", "b"},
+			{"media", "radar.gif", "image/gif", "gif"},
+		}, false},
+		{"notStruct", NewMultipartBody().Struct("foo"), nil, true},
+		{"missingFile", NewMultipartBody().FilePath("data", filepath.Join(dir, "missing")), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := tt.body.Body()
+			if err == nil {
+				var parts []part
+				r := multipart.NewReader(body, tt.body.boundary)
+				for {
+					p, perr := r.NextPart()
+					if perr != nil {
+						if !strings.Contains(perr.Error(), "EOF") {
+							err = perr
+						}
+						break
+					}
+					content, _ := ioutil.ReadAll(p)
+					parts = append(parts, part{p.FormName(), p.FileName(), p.Header.Get(contentType), string(content)})
+				}
+				if err == nil && !assert.Equal(t, tt.want, parts) {
+					t.Errorf("%v MultipartBody.Body() = %v, want %v", tt.name, parts, tt.want)
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("%v MultipartBody.Body() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_BodyMultipart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		file, header, err := r.FormFile("media")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		content, _ := ioutil.ReadAll(file)
+		w.Write([]byte(r.FormValue("status") + " " + header.Filename + " " + string(content)))
+	}))
+	defer server.Close()
+
+	body := NewMultipartBody().
+		Field("status", "uploading").
+		File("media", "radar.gif", "", strings.NewReader("gif"))
+	svc := New().Base(server.URL).Post().BodyMultipart(body).BinarySuccessResponder()
+
+	req, err := svc.Request()
+	if err != nil {
+		t.Fatalf("Service.Request() error = %v", err)
+	}
+	assert.Equal(t, body.ContentType(), req.Header.Get(contentType))
+
+	resp, err := svc.Do(req)
+	if err != nil {
+		t.Fatalf("Service.Do() error = %v", err)
+	}
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []byte("uploading radar.gif gif"), svc.GetSuccess())
+}
+
+func TestMultipartBody_Rewindable(t *testing.T) {
+	tests := []struct {
+		name string
+		body *MultipartBody
+		want bool
+	}{
+		{"fields", NewMultipartBody().Field("foo", "bar"), true},
+		{"seeker", NewMultipartBody().File("image", "map.png", "", strings.NewReader("png")), true},
+		{"path", NewMultipartBody().FilePath("data", "forecast.json"), true},
+		{"reader", NewMultipartBody().File("image", "map.png", "", ioutil.NopCloser(strings.NewReader("png"))), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.body.Rewindable(); got != tt.want {
+				t.Errorf("%v MultipartBody.Rewindable() = %v, want %v", tt.name, got, tt.want)
+			}
+			body, err := tt.body.Body()
+			if err != nil {
+				t.Fatalf("%v MultipartBody.Body() error = %v", tt.name, err)
+			}
+			body.(io.Closer).Close()
+			if _, err := tt.body.Body(); (err != nil) == tt.want {
+				t.Errorf("%v second MultipartBody.Body() error = %v, want error %v", tt.name, err, !tt.want)
+			}
+		})
+	}
+}
+
+func TestService_BodyMultipart_retry(t *testing.T) {
+	var mu sync.Mutex
+	var uploads []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		content := ""
+		if file, _, err := r.FormFile("media"); err == nil {
+			b, _ := ioutil.ReadAll(file)
+			content = string(b)
+		}
+		uploads = append(uploads, content)
+		if len(uploads) < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		r    io.Reader
+		want []string
+	}{
+		{"seeker", strings.NewReader("gif"), []string{"gif", "gif"}},
+		{"reader", ioutil.NopCloser(strings.NewReader("gif")), []string{"gif"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			uploads = nil
+			mu.Unlock()
+			body := NewMultipartBody().File("media", "radar.gif", "", tt.r)
+			_, err := New().Post(server.URL).Set("Idempotency-Key", tt.name).BodyMultipart(body).Retry(newTestRetryPolicy(3)).APIErrors(false).Do()
+			if err != nil {
+				t.Fatalf("%v Service.Do() error = %v", tt.name, err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if !assert.Equal(t, tt.want, uploads) {
+				t.Errorf("%v uploads = %v, want %v", tt.name, uploads, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_BodyMultipart_retryUnread(t *testing.T) {
+	// the first response is sent before the body is read, so the retry
+	// rewinds the parts while the first body may still be written
+	var mu sync.Mutex
+	var uploads []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if uploads == nil {
+			uploads = []int{}
+			w.Header().Set("Connection", "close")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		size := 0
+		if file, _, err := r.FormFile("media"); err == nil {
+			b, _ := ioutil.ReadAll(file)
+			size = len(b)
+		}
+		uploads = append(uploads, size)
+	}))
+	defer server.Close()
+
+	content := strings.Repeat("radar", 1<<18)
+	body := NewMultipartBody().File("media", "radar.gif", "", strings.NewReader(content))
+	_, err := New().Put(server.URL).BodyMultipart(body).Retry(newTestRetryPolicy(3)).Do()
+	if err != nil {
+		t.Fatalf("Service.Do() error = %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []int{len(content)}, uploads)
+}
@@ -0,0 +1,30 @@
+package meteor
+
+import (
+	"bytes"
+	"io"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// protobufBodyProvider encodes a protobuf message as a Body for requests.
+// See https://pkg.go.dev/google.golang.org/protobuf/proto#Marshal for details.
+type protobufBodyProvider struct {
+	payload proto.Message
+}
+
+// ContentType gets the content type (protobufContentType) of the body.
+// Implements BodyProvider interface
+func (p protobufBodyProvider) ContentType() string {
+	return protobufContentType
+}
+
+// Body returns the body of the provider
+// Implements BodyProvider interface
+func (p protobufBodyProvider) Body() (io.Reader, error) {
+	data, err := proto.Marshal(p.payload)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
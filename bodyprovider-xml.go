@@ -0,0 +1,30 @@
+package meteor
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+)
+
+// xmlBodyProvider encodes an XML tagged struct value as a Body for requests.
+// See https://golang.org/pkg/encoding/xml/#Marshal for details.
+type xmlBodyProvider struct {
+	payload interface{}
+}
+
+// ContentType gets the content type (xmlContentType) of the body.
+// Implements BodyProvider interface
+func (p xmlBodyProvider) ContentType() string {
+	return xmlContentType
+}
+
+// Body returns the body of the provider
+// Implements BodyProvider interface
+func (p xmlBodyProvider) Body() (io.Reader, error) {
+	buf := &bytes.Buffer{}
+	err := xml.NewEncoder(buf).Encode(p.payload)
+	if err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
@@ -0,0 +1,45 @@
+package meteor
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func Test_xmlBodyProvider_ContentType(t *testing.T) {
+	if got := (xmlBodyProvider{}).ContentType(); got != xmlContentType {
+		t.Errorf("xmlBodyProvider.ContentType() = %v, want %v", got, xmlContentType)
+	}
+}
+
+func Test_xmlBodyProvider_Body(t *testing.T) {
+	type Observation struct {
+		Station string  `xml:"station,attr"`
+		Temp    float64 `xml:"temp"`
+	}
+
+	tests := []struct {
+		name    string
+		p       xmlBodyProvider
+		want    string
+		wantErr bool
+	}{
+		{"struct", xmlBodyProvider{&Observation{"KATL", 21.5}}, `<Observation station="KATL"><temp>21.5</temp></Observation>`, false},
+		{"unsupported", xmlBodyProvider{map[string]string{"station": "KATL"}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.p.Body()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("xmlBodyProvider.Body() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			body, _ := ioutil.ReadAll(got)
+			if string(body) != tt.want {
+				t.Errorf("xmlBodyProvider.Body() = %s, want %s", body, tt.want)
+			}
+		})
+	}
+}
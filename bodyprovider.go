@@ -5,15 +5,18 @@ package meteor
 import (
 	"errors"
 	"io"
+	"io/ioutil"
 )
 
 const (
-	jpegContentType = "image/jpeg"
-	pngContentType  = "image/png"
-	gifContentType  = "image/gif"
-	textContentType = "text/plain"
-	jsonContentType = "application/json"
-	formContentType = "application/x-www-form-urlencoded"
+	jpegContentType     = "image/jpeg"
+	pngContentType      = "image/png"
+	gifContentType      = "image/gif"
+	textContentType     = "text/plain"
+	jsonContentType     = "application/json"
+	xmlContentType      = "application/xml"
+	protobufContentType = "application/x-protobuf"
+	formContentType     = "application/x-www-form-urlencoded"
 )
 
 // BodyProvider provides Body content for http.Request attachment.
@@ -43,3 +46,46 @@ func (p bodyProvider) Body() (io.Reader, error) {
 	}
 	return p.body, nil
 }
+
+// RewindableBodyProvider is a BodyProvider that can tell whether its Body
+// returns a fresh body on every call. Request bodies are only resent by
+// retries, hedging and pagination when they can be rewound: bodies that
+// implement io.Seeker, or bodies of a RewindableBodyProvider.
+type RewindableBodyProvider interface {
+	BodyProvider
+	// Rewindable reports whether Body can be called again for a fresh body.
+	Rewindable() bool
+}
+
+// getBody returns a function that gets a fresh body for http.Request.GetBody,
+// from the provider if it is rewindable, or by rewinding the body if it
+// implements io.Seeker. It returns nil if the body cannot be rewound.
+func getBody(provider BodyProvider, body io.Reader) func() (io.ReadCloser, error) {
+	if p, ok := provider.(RewindableBodyProvider); ok && p.Rewindable() {
+		return func() (io.ReadCloser, error) {
+			body, err := provider.Body()
+			if err != nil {
+				return nil, err
+			}
+			return readCloser(body), nil
+		}
+	}
+	seeker, ok := body.(io.Seeker)
+	if !ok {
+		return nil
+	}
+	return func() (io.ReadCloser, error) {
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return readCloser(body), nil
+	}
+}
+
+// readCloser returns the body as an io.ReadCloser.
+func readCloser(body io.Reader) io.ReadCloser {
+	if rc, ok := body.(io.ReadCloser); ok {
+		return rc
+	}
+	return ioutil.NopCloser(body)
+}
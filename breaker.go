@@ -0,0 +1,201 @@
+package meteor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFailureThreshold is the default number of consecutive failures
+	// that opens a circuit.
+	DefaultFailureThreshold = 5
+	// DefaultCoolDown is the default time a circuit stays open.
+	DefaultCoolDown = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned, wrapped with the key of the circuit, when a
+// request is not sent because its circuit is open.
+var ErrCircuitOpen = errors.New("meteor: circuit open")
+
+// CircuitState is the state of a circuit.
+type CircuitState int
+
+const (
+	// CircuitClosed lets every request through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails every request with ErrCircuitOpen.
+	CircuitOpen
+	// CircuitHalfOpen lets a single trial request through, which closes the
+	// circuit if it succeeds or opens it again if it fails.
+	CircuitHalfOpen
+)
+
+// String implements the fmt.Stringer interface.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "halfOpen"
+	}
+	return "unknown"
+}
+
+// CircuitBreaker fails requests fast once their host, or the key given by
+// Key, has failed FailureThreshold times in a row. After CoolDown, a trial
+// request is let through to probe whether it has recovered. A CircuitBreaker
+// is safe for concurrent use, and its state is shared by every Service using it.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failures that opens a circuit.
+	FailureThreshold int
+
+	// CoolDown is the time a circuit stays open before a trial request is let through.
+	CoolDown time.Duration
+
+	// Key returns the key of the circuit of the request. If nil, the
+	// request's host is used.
+	Key func(*http.Request) string
+
+	// IsFailure reports whether a response or error is a failure. If nil,
+	// errors and 5XX responses are failures. Requests cancelled by their
+	// context are never failures.
+	IsFailure func(*http.Response, error) bool
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// circuit is the state of the requests sharing a key.
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// NewCircuitBreaker returns a CircuitBreaker keyed by host opening after
+// failureThreshold consecutive failures for coolDown. Values of 0 or less
+// use DefaultFailureThreshold and DefaultCoolDown.
+func NewCircuitBreaker(failureThreshold int, coolDown time.Duration) *CircuitBreaker {
+	if failureThreshold <= 0 {
+		failureThreshold = DefaultFailureThreshold
+	}
+	if coolDown <= 0 {
+		coolDown = DefaultCoolDown
+	}
+	return &CircuitBreaker{
+		FailureThreshold: failureThreshold,
+		CoolDown:         coolDown,
+	}
+}
+
+// State gets the state of the circuit of key.
+func (b *CircuitBreaker) State(key string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[key]
+	if !ok {
+		return CircuitClosed
+	}
+	if c.state == CircuitOpen && time.Since(c.openedAt) >= b.CoolDown {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+// Middleware returns the CircuitBreaker as a Middleware, failing requests
+// with ErrCircuitOpen while their circuit is open. The bodies of failed
+// requests are closed.
+func (b *CircuitBreaker) Middleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			key := b.key(req)
+			if !b.allow(key) {
+				closeRequestBody(req)
+				return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, key)
+			}
+			resp, err := next.Do(req)
+			b.record(key, resp, err)
+			return resp, err
+		})
+	}
+}
+
+// key gets the key of the circuit of the request.
+func (b *CircuitBreaker) key(req *http.Request) string {
+	if b.Key != nil {
+		return b.Key(req)
+	}
+	return req.URL.Host
+}
+
+// isFailure reports whether the response or error is a failure.
+func (b *CircuitBreaker) isFailure(resp *http.Response, err error) bool {
+	if b.IsFailure != nil {
+		return b.IsFailure(resp, err)
+	}
+	return err != nil || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// allow reports whether a request of the circuit of key may be sent.
+func (b *CircuitBreaker) allow(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[key]
+	if !ok {
+		return true
+	}
+	switch c.state {
+	case CircuitOpen:
+		if time.Since(c.openedAt) < b.CoolDown {
+			return false
+		}
+		c.state = CircuitHalfOpen
+		c.trial = true
+		return true
+	case CircuitHalfOpen:
+		if c.trial {
+			return false
+		}
+		c.trial = true
+		return true
+	}
+	return true
+}
+
+// record records the outcome of a request of the circuit of key.
+func (b *CircuitBreaker) record(key string, resp *http.Response, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.circuits == nil {
+		b.circuits = make(map[string]*circuit)
+	}
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+
+	if errors.Is(err, context.Canceled) {
+		// neither a success nor a failure, let another trial through
+		c.trial = false
+		return
+	}
+	if !b.isFailure(resp, err) {
+		delete(b.circuits, key)
+		return
+	}
+
+	c.failures++
+	if c.state == CircuitHalfOpen || c.failures >= b.FailureThreshold {
+		c.state = CircuitOpen
+		c.openedAt = time.Now()
+		c.trial = false
+	}
+}
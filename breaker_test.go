@@ -0,0 +1,139 @@
+package meteor
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestService_CircuitBreaker(t *testing.T) {
+	breaker := NewCircuitBreaker(2, time.Second)
+	tests := []struct {
+		name string
+		s    *Service
+		want *CircuitBreaker
+	}{
+		{"default", New(), nil},
+		{"breaker", New().CircuitBreaker(breaker), breaker},
+		{"breakerNew", New().CircuitBreaker(breaker).New(), breaker},
+		{"breakerReset", New().CircuitBreaker(breaker).Reset(), nil},
+		{"breakerNil", New().CircuitBreaker(breaker).CircuitBreaker(nil), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.breaker; got != tt.want {
+				t.Errorf("%v Service.CircuitBreaker() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_Do_circuitBreaker(t *testing.T) {
+	var down int32 = 1
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"text": "up"}`))
+	}))
+	defer server.Close()
+
+	breaker := NewCircuitBreaker(2, 50*time.Millisecond)
+	parent := New().Base(server.URL).CircuitBreaker(breaker)
+	key := server.Listener.Addr().String()
+
+	// two failures open the circuit shared by the children
+	for i := 0; i < 2; i++ {
+		if _, err := parent.New().Do(); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Service.Do() error = %v before the threshold", err)
+		}
+	}
+	if got := breaker.State(key); got != CircuitOpen {
+		t.Fatalf("CircuitBreaker.State() = %v, want %v", got, CircuitOpen)
+	}
+	_, err := parent.New().Do()
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Service.Do() error = %v, want %v", err, ErrCircuitOpen)
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("requests sent = %v, want 2", got)
+	}
+
+	// after the cool down, a failing trial opens the circuit again
+	time.Sleep(60 * time.Millisecond)
+	if got := breaker.State(key); got != CircuitHalfOpen {
+		t.Errorf("CircuitBreaker.State() = %v, want %v", got, CircuitHalfOpen)
+	}
+	if _, err := parent.New().Do(); errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Service.Do() error = %v, want the trial request to be sent", err)
+	}
+	if got := breaker.State(key); got != CircuitOpen {
+		t.Errorf("CircuitBreaker.State() = %v, want %v", got, CircuitOpen)
+	}
+
+	// a successful trial closes the circuit
+	atomic.StoreInt32(&down, 0)
+	time.Sleep(60 * time.Millisecond)
+	if _, err := parent.New().Do(); err != nil {
+		t.Errorf("Service.Do() error = %v", err)
+	}
+	if got := breaker.State(key); got != CircuitClosed {
+		t.Errorf("CircuitBreaker.State() = %v, want %v", got, CircuitClosed)
+	}
+}
+
+func TestCircuitBreaker_Middleware(t *testing.T) {
+	failing := DoerFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("boom")
+	})
+	breaker := NewCircuitBreaker(1, time.Minute)
+	breaker.Key = func(req *http.Request) string {
+		return req.URL.Path
+	}
+	doer := Chain(failing, breaker.Middleware())
+
+	tests := []struct {
+		name     string
+		path     string
+		wantOpen bool
+	}{
+		{"firstFailure", "https://example.com/a", false},
+		{"open", "https://example.com/a", true},
+		{"otherKey", "https://example.com/b", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &closeRecorder{Reader: strings.NewReader("body")}
+			req, _ := http.NewRequest("POST", tt.path, body)
+			_, err := doer.Do(req)
+			if err == nil {
+				t.Fatalf("%v Doer.Do() error = nil", tt.name)
+			}
+			if got := errors.Is(err, ErrCircuitOpen); got != tt.wantOpen {
+				t.Errorf("%v Doer.Do() error = %v, want open %v", tt.name, err, tt.wantOpen)
+			}
+			if tt.wantOpen && !body.closed {
+				t.Errorf("%v Doer.Do() did not close the request body", tt.name)
+			}
+		})
+	}
+}
+
+// closeRecorder is a request body recording whether it was closed.
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
@@ -0,0 +1,66 @@
+package meteor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// diskCacheStore is a CacheStore keeping every response in a file of its
+// directory.
+type diskCacheStore struct {
+	dir string
+}
+
+// NewDiskCacheStore returns a CacheStore keeping responses in files of dir,
+// which is created if needed.
+func NewDiskCacheStore(dir string) (CacheStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &diskCacheStore{dir: dir}, nil
+}
+
+// path gets the file of key.
+func (s *diskCacheStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:]))
+}
+
+// Get gets the value of key, if any.
+// Implements CacheStore interface
+func (s *diskCacheStore) Get(key string) ([]byte, bool) {
+	value, err := ioutil.ReadFile(s.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set sets the value of key. The file is replaced atomically, so
+// concurrent readers never see a partial value.
+// Implements CacheStore interface
+func (s *diskCacheStore) Set(key string, value []byte) {
+	f, err := ioutil.TempFile(s.dir, "tmp-")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(value)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), s.path(key))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+}
+
+// Delete deletes the value of key.
+// Implements CacheStore interface
+func (s *diskCacheStore) Delete(key string) {
+	os.Remove(s.path(key))
+}
@@ -0,0 +1,74 @@
+package meteor
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lruCacheStore is an in-memory CacheStore evicting the least recently
+// used entries.
+type lruCacheStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+}
+
+// lruEntry is an entry of a lruCacheStore.
+type lruEntry struct {
+	key   string
+	value []byte
+}
+
+// NewLRUCacheStore returns an in-memory CacheStore holding at most
+// maxEntries responses. A value of 0 or less means no limit.
+func NewLRUCacheStore(maxEntries int) CacheStore {
+	return &lruCacheStore{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Get gets the value of key, if any.
+// Implements CacheStore interface
+func (s *lruCacheStore) Get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	s.order.MoveToFront(e)
+	return e.Value.(*lruEntry).value, true
+}
+
+// Set sets the value of key, evicting the least recently used entry if the
+// store is full.
+// Implements CacheStore interface
+func (s *lruCacheStore) Set(key string, value []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[key]; ok {
+		e.Value.(*lruEntry).value = value
+		s.order.MoveToFront(e)
+		return
+	}
+	s.entries[key] = s.order.PushFront(&lruEntry{key, value})
+	if s.maxEntries > 0 && s.order.Len() > s.maxEntries {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Delete deletes the value of key.
+// Implements CacheStore interface
+func (s *lruCacheStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[key]; ok {
+		s.order.Remove(e)
+		delete(s.entries, key)
+	}
+}
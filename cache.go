@@ -0,0 +1,368 @@
+package meteor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// CacheStatusHeader is the response header telling how a response was
+	// served by a Cache: CacheHit, CacheMiss, CacheRevalidated or CacheStale.
+	CacheStatusHeader = "X-Meteor-Cache"
+
+	// CacheHit is a fresh response served from the cache.
+	CacheHit = "HIT"
+	// CacheMiss is a response served from the network.
+	CacheMiss = "MISS"
+	// CacheRevalidated is a cached response the server confirmed with a 304.
+	CacheRevalidated = "REVALIDATED"
+	// CacheStale is a stale response served because of stale-while-revalidate
+	// or stale-if-error.
+	CacheStale = "STALE"
+
+	// cacheStoredHeader records when a response was stored.
+	cacheStoredHeader = "X-Meteor-Cache-Stored"
+	// cacheVaryHeader prefixes the request headers named by Vary.
+	cacheVaryHeader = "X-Meteor-Cache-Vary-"
+)
+
+// CacheStore stores cached responses by key. Implementations must be safe
+// for concurrent use.
+type CacheStore interface {
+	// Get gets the value of key, if any.
+	Get(key string) ([]byte, bool)
+	// Set sets the value of key.
+	Set(key string, value []byte)
+	// Delete deletes the value of key.
+	Delete(key string)
+}
+
+// Cache is a private HTTP cache following RFC 7234 for GET and HEAD
+// requests. Fresh responses are served without the network, stale ones are
+// revalidated with If-None-Match and If-Modified-Since, and the
+// stale-while-revalidate and stale-if-error directives are honored. Cached
+// responses have the same body as live ones, so Responders decode them alike.
+// Responses are cached per credentials: requests with another Authorization
+// or Cookie header never share them.
+type Cache struct {
+	// Store stores the cached responses.
+	Store CacheStore
+
+	revalidating sync.Map
+}
+
+// NewCache returns a Cache using the given store.
+func NewCache(store CacheStore) *Cache {
+	return &Cache{Store: store}
+}
+
+// Middleware returns the Cache as a Middleware.
+func (c *Cache) Middleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return c.do(next, req)
+		})
+	}
+}
+
+// cacheCredentialHeaders are the request headers identifying a user, so
+// responses cached for one user are never served to another.
+var cacheCredentialHeaders = []string{"Authorization", "Cookie"}
+
+// cacheKey gets the key of the request. Requests with credentials are keyed
+// by a hash of them too.
+func cacheKey(req *http.Request) string {
+	return cacheMethodKey(req.Method, req)
+}
+
+// cacheMethodKey gets the key of the request sent with the method.
+func cacheMethodKey(method string, req *http.Request) string {
+	key := method + " " + req.URL.String()
+	hash := sha256.New()
+	credentials := false
+	for _, name := range cacheCredentialHeaders {
+		for _, value := range req.Header.Values(name) {
+			credentials = true
+			fmt.Fprintf(hash, "%s: %s\n", name, value)
+		}
+	}
+	if credentials {
+		key += " " + hex.EncodeToString(hash.Sum(nil))
+	}
+	return key
+}
+
+// do serves the request from the cache or the network.
+func (c *Cache) do(next Doer, req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		resp, err := next.Do(req)
+		if err == nil && resp.StatusCode < http.StatusBadRequest {
+			// unsafe methods invalidate the cached responses of the URL
+			c.Store.Delete(cacheMethodKey(http.MethodGet, req))
+			c.Store.Delete(cacheMethodKey(http.MethodHead, req))
+		}
+		return resp, err
+	}
+	reqCC := parseCacheControl(req.Header)
+	if _, ok := reqCC["no-store"]; ok {
+		return next.Do(req)
+	}
+
+	key := cacheKey(req)
+	cached := c.load(key, req)
+	if cached == nil {
+		return c.fetch(next, req, key)
+	}
+
+	respCC := parseCacheControl(cached.Header)
+	_, reqNoCache := reqCC["no-cache"]
+	_, respNoCache := respCC["no-cache"]
+	age, lifetime := cacheAge(cached), cacheLifetime(cached, respCC)
+	if !reqNoCache && !respNoCache && age < lifetime {
+		closeRequestBody(req)
+		cached.Header.Set(CacheStatusHeader, CacheHit)
+		return cached, nil
+	}
+
+	if !reqNoCache && !respNoCache && age < lifetime+cacheSeconds(respCC, "stale-while-revalidate") {
+		c.revalidateLater(next, req, key)
+		cached.Header.Set(CacheStatusHeader, CacheStale)
+		return cached, nil
+	}
+
+	resp, err := c.revalidate(next, req, key, cached)
+	if (err != nil || resp.StatusCode >= http.StatusInternalServerError) &&
+		age < lifetime+cacheSeconds(respCC, "stale-if-error") {
+		if resp != nil {
+			drainBody(resp)
+		}
+		cached.Header.Set(CacheStatusHeader, CacheStale)
+		return cached, nil
+	}
+	return resp, err
+}
+
+// fetch sends the request and stores the response if it is cacheable.
+func (c *Cache) fetch(next Doer, req *http.Request, key string) (*http.Response, error) {
+	resp, err := next.Do(req)
+	if err != nil {
+		return resp, err
+	}
+	if resp, err = c.store(key, req, resp); err != nil {
+		return resp, err
+	}
+	resp.Header.Set(CacheStatusHeader, CacheMiss)
+	return resp, nil
+}
+
+// revalidate sends a conditional request for the cached response.
+func (c *Cache) revalidate(next Doer, req *http.Request, key string, cached *http.Response) (*http.Response, error) {
+	conditional := req.Clone(req.Context())
+	if etag := cached.Header.Get("ETag"); etag != "" {
+		conditional.Header.Set("If-None-Match", etag)
+	}
+	if modified := cached.Header.Get("Last-Modified"); modified != "" {
+		conditional.Header.Set("If-Modified-Since", modified)
+	}
+
+	resp, err := next.Do(conditional)
+	if err != nil || resp.StatusCode != http.StatusNotModified {
+		if err == nil && resp.StatusCode < http.StatusInternalServerError {
+			resp, err = c.store(key, req, resp)
+			if err == nil {
+				resp.Header.Set(CacheStatusHeader, CacheMiss)
+			}
+		}
+		return resp, err
+	}
+
+	// a 304 updates the headers of the cached response
+	drainBody(resp)
+	for k, v := range resp.Header {
+		cached.Header[k] = v
+	}
+	cached.Header.Del(CacheStatusHeader)
+	c.save(key, req, cached)
+	cached.Header.Set(CacheStatusHeader, CacheRevalidated)
+	return cached, nil
+}
+
+// revalidateLater revalidates the cached response of key in the background,
+// once at a time.
+func (c *Cache) revalidateLater(next Doer, req *http.Request, key string) {
+	if _, loaded := c.revalidating.LoadOrStore(key, true); loaded {
+		return
+	}
+	r := req.Clone(context.WithoutCancel(req.Context()))
+	go func() {
+		defer c.revalidating.Delete(key)
+		if cached := c.load(key, r); cached != nil {
+			if resp, err := c.revalidate(next, r, key, cached); err == nil {
+				drainBody(resp)
+			}
+		}
+	}()
+}
+
+// store reads the body of the response and stores it if it is cacheable,
+// or deletes key otherwise. The returned response has the same body.
+func (c *Cache) store(key string, req *http.Request, resp *http.Response) (*http.Response, error) {
+	if !cacheable(resp) {
+		c.Store.Delete(key)
+		return resp, nil
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return resp, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.TransferEncoding = nil
+	c.save(key, req, resp)
+	return resp, nil
+}
+
+// save stores the response with the time it is stored and the request
+// headers named by Vary, leaving its body readable.
+func (c *Cache) save(key string, req *http.Request, resp *http.Response) {
+	fields := varyFields(resp.Header)
+	resp.Header.Set(cacheStoredHeader, strconv.FormatInt(time.Now().UnixNano(), 10))
+	for _, field := range fields {
+		resp.Header.Set(cacheVaryHeader+field, req.Header.Get(field))
+	}
+	if data, err := httputil.DumpResponse(resp, true); err == nil {
+		c.Store.Set(key, data)
+	}
+	resp.Header.Del(cacheStoredHeader)
+	for _, field := range fields {
+		resp.Header.Del(cacheVaryHeader + field)
+	}
+}
+
+// load gets the cached response of key if it may be used for req.
+func (c *Cache) load(key string, req *http.Request) *http.Response {
+	data, ok := c.Store.Get(key)
+	if !ok {
+		return nil
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), req)
+	if err != nil {
+		c.Store.Delete(key)
+		return nil
+	}
+	for _, field := range varyFields(resp.Header) {
+		if field == "*" || resp.Header.Get(cacheVaryHeader+field) != req.Header.Get(field) {
+			return nil
+		}
+		resp.Header.Del(cacheVaryHeader + field)
+	}
+	return resp
+}
+
+// cacheable reports whether the response may be stored.
+func cacheable(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusMultipleChoices,
+		http.StatusMovedPermanently, http.StatusNotFound, http.StatusGone:
+	default:
+		return false
+	}
+	cc := parseCacheControl(resp.Header)
+	if _, ok := cc["no-store"]; ok {
+		return false
+	}
+	_, maxAge := cc["max-age"]
+	return maxAge || resp.Header.Get("Expires") != "" ||
+		resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""
+}
+
+// cacheAge gets the current age of the cached response (RFC 7234, 4.2.3).
+func cacheAge(resp *http.Response) time.Duration {
+	stored := time.Now()
+	if ns, err := strconv.ParseInt(resp.Header.Get(cacheStoredHeader), 10, 64); err == nil {
+		stored = time.Unix(0, ns)
+	}
+	resp.Header.Del(cacheStoredHeader)
+
+	var age time.Duration
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil && stored.After(date) {
+		age = stored.Sub(date)
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Age")); err == nil && time.Duration(seconds)*time.Second > age {
+		age = time.Duration(seconds) * time.Second
+	}
+	return age + time.Since(stored)
+}
+
+// cacheLifetime gets the freshness lifetime of the response (RFC 7234, 4.2.1).
+func cacheLifetime(resp *http.Response, cc map[string]string) time.Duration {
+	if _, ok := cc["max-age"]; ok {
+		return cacheSeconds(cc, "max-age")
+	}
+	expires, err := http.ParseTime(resp.Header.Get("Expires"))
+	if err != nil {
+		return 0
+	}
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return time.Until(expires)
+	}
+	return expires.Sub(date)
+}
+
+// cacheSeconds gets a Cache-Control directive given in seconds.
+func cacheSeconds(cc map[string]string, directive string) time.Duration {
+	seconds, err := strconv.Atoi(cc[directive])
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// parseCacheControl parses the Cache-Control header into its directives.
+func parseCacheControl(header http.Header) map[string]string {
+	cc := make(map[string]string)
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			directive = strings.TrimSpace(directive)
+			if directive == "" {
+				continue
+			}
+			name, arg, _ := strings.Cut(directive, "=")
+			cc[strings.ToLower(name)] = strings.Trim(arg, `"`)
+		}
+	}
+	return cc
+}
+
+// varyFields gets the request header fields named by the Vary header.
+func varyFields(header http.Header) []string {
+	var fields []string
+	for _, value := range header.Values("Vary") {
+		for _, field := range strings.Split(value, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				fields = append(fields, http.CanonicalHeaderKey(field))
+			}
+		}
+	}
+	return fields
+}
+
+// drainBody drains and closes the response body so its connection can be reused.
+func drainBody(resp *http.Response) {
+	io.CopyN(ioutil.Discard, resp.Body, NBytes)
+	resp.Body.Close()
+}
@@ -0,0 +1,196 @@
+package meteor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_Cache(t *testing.T) {
+	tests := []struct {
+		name         string
+		cacheControl string
+		fail         bool
+		method       string
+		want         []string
+		wantRequests int32
+	}{
+		{"fresh", "max-age=60", false, "GET", []string{CacheMiss, CacheHit, CacheHit}, 1},
+		{"revalidated", "max-age=0", false, "GET", []string{CacheMiss, CacheRevalidated, CacheRevalidated}, 3},
+		{"noStore", "no-store", false, "GET", []string{CacheMiss, CacheMiss, CacheMiss}, 3},
+		{"noCache", "no-cache, max-age=60", false, "GET", []string{CacheMiss, CacheRevalidated, CacheRevalidated}, 3},
+		{"staleIfError", "max-age=0, stale-if-error=60", true, "GET", []string{CacheMiss, CacheStale, CacheStale}, 3},
+		{"staleIfErrorExpired", "max-age=0", true, "GET", []string{CacheMiss, "", ""}, 3},
+		{"post", "max-age=60", false, "POST", []string{"", "", ""}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&requests, 1)
+				if tt.fail && n > 1 {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				w.Header().Set("Cache-Control", tt.cacheControl)
+				w.Header().Set("ETag", `"v1"`)
+				if r.Header.Get("If-None-Match") == `"v1"` {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				w.Write([]byte(`{"text": "cached", "temperature": 21.5}`))
+			}))
+			defer server.Close()
+
+			svc := New().Base(server.URL).Method(tt.method).Cache(NewCache(NewLRUCacheStore(10)))
+			for i, want := range tt.want {
+				success := &FakeModel{}
+				resp, _ := svc.New().JSONSuccessResponder(success).Do()
+				if got := resp.Header.Get(CacheStatusHeader); got != want {
+					t.Errorf("%v Service.Do() #%v %v = %q, want %q", tt.name, i, CacheStatusHeader, got, want)
+				}
+				if want == "" {
+					continue
+				}
+				// cached bodies are decoded like live ones
+				if !assert.Equal(t, &FakeModel{Text: "cached", Temperature: 21.5}, success) {
+					t.Errorf("%v Service.Do() #%v success = %v", tt.name, i, success)
+				}
+			}
+			if got := atomic.LoadInt32(&requests); got != tt.wantRequests {
+				t.Errorf("%v requests sent = %v, want %v", tt.name, got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestService_Cache_staleWhileRevalidate(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		w.Header().Set("Cache-Control", "max-age=0, stale-while-revalidate=60")
+		w.Write([]byte(`{"text": "` + strconv.Itoa(int(n)) + `"}`))
+	}))
+	defer server.Close()
+
+	svc := New().Base(server.URL).Cache(NewCache(NewLRUCacheStore(10)))
+	svc.New().Do()
+
+	success := &FakeModel{}
+	resp, err := svc.New().JSONSuccessResponder(success).Do()
+	if err != nil {
+		t.Fatalf("Service.Do() error = %v", err)
+	}
+	if got := resp.Header.Get(CacheStatusHeader); got != CacheStale || success.Text != "1" {
+		t.Errorf("Service.Do() = %v %v, want the stale response", got, success.Text)
+	}
+
+	// the response is revalidated in the background
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&requests) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("requests sent = %v, want 2", got)
+	}
+}
+
+func TestService_Cache_vary(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Language")
+		w.Write([]byte(`{"text": "` + r.Header.Get("Accept-Language") + `"}`))
+	}))
+	defer server.Close()
+
+	svc := New().Base(server.URL).Cache(NewCache(NewLRUCacheStore(10)))
+	for _, lang := range []string{"en", "en", "fr"} {
+		success := &FakeModel{}
+		if _, err := svc.New().Set("Accept-Language", lang).JSONSuccessResponder(success).Do(); err != nil {
+			t.Fatalf("Service.Do() error = %v", err)
+		}
+		if success.Text != lang {
+			t.Errorf("Service.Do() success = %v, want %v", success.Text, lang)
+		}
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("requests sent = %v, want 2", got)
+	}
+}
+
+func TestService_Cache_credentials(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte(`{"text": "` + r.Header.Get("Authorization") + `"}`))
+	}))
+	defer server.Close()
+
+	svc := New().Base(server.URL).Cache(NewCache(NewLRUCacheStore(10)))
+	for _, auth := range []string{"Bearer alice", "Bearer alice", "Bearer bob", ""} {
+		success := &FakeModel{}
+		if _, err := svc.New().Set("Authorization", auth).JSONSuccessResponder(success).Do(); err != nil {
+			t.Fatalf("Service.Do() error = %v", err)
+		}
+		if success.Text != auth {
+			t.Errorf("Service.Do() success = %v, want %v", success.Text, auth)
+		}
+	}
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("requests sent = %v, want 3", got)
+	}
+}
+
+func TestCacheStore(t *testing.T) {
+	disk, err := NewDiskCacheStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskCacheStore() error = %v", err)
+	}
+	tests := []struct {
+		name  string
+		store CacheStore
+	}{
+		{"lru", NewLRUCacheStore(2)},
+		{"disk", disk},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := tt.store.Get("a"); ok {
+				t.Errorf("%v CacheStore.Get() found a missing key", tt.name)
+			}
+			tt.store.Set("a", []byte("1"))
+			tt.store.Set("a", []byte("2"))
+			if got, ok := tt.store.Get("a"); !ok || string(got) != "2" {
+				t.Errorf("%v CacheStore.Get() = %q, %v, want %q", tt.name, got, ok, "2")
+			}
+			tt.store.Delete("a")
+			if _, ok := tt.store.Get("a"); ok {
+				t.Errorf("%v CacheStore.Get() found a deleted key", tt.name)
+			}
+		})
+	}
+}
+
+func TestLRUCacheStore_evict(t *testing.T) {
+	store := NewLRUCacheStore(2)
+	store.Set("a", []byte("a"))
+	store.Set("b", []byte("b"))
+	store.Get("a")
+	store.Set("c", []byte("c"))
+	if _, ok := store.Get("b"); ok {
+		t.Errorf("CacheStore.Get() found the least recently used key")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := store.Get(key); !ok {
+			t.Errorf("CacheStore.Get(%v) = missing", key)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package meteor
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Coalescer shares one upstream call between identical in-flight GET and
+// HEAD requests, that is requests with the same method, URL and headers.
+// Every caller gets its own copy of the response, so each Responder decodes
+// it on its own. A Coalescer is safe for concurrent use.
+type Coalescer struct {
+	// VaryHeaders are the request headers that tell identical requests
+	// apart. If empty, every request header does, so requests with other
+	// credentials, such as an API key header, never share a response.
+	VaryHeaders []string
+
+	mu    sync.Mutex
+	calls map[string]*coalescedCall
+}
+
+// coalescedCall is an upstream call shared by its waiters.
+type coalescedCall struct {
+	done    chan struct{}
+	resp    *http.Response
+	body    []byte
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// NewCoalescer returns a Coalescer telling requests apart by the given
+// headers, or by all their headers if none are given. Only list headers if
+// the others, including credentials, never change the response.
+func NewCoalescer(varyHeaders ...string) *Coalescer {
+	return &Coalescer{VaryHeaders: varyHeaders}
+}
+
+// Middleware returns the Coalescer as a Middleware.
+func (c *Coalescer) Middleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return c.do(next, req)
+		})
+	}
+}
+
+// key gets the key of identical requests.
+func (c *Coalescer) key(req *http.Request) string {
+	key := &strings.Builder{}
+	key.WriteString(req.Method + " " + req.URL.String())
+	names := c.VaryHeaders
+	if len(names) == 0 {
+		names = make([]string, 0, len(req.Header))
+		for name := range req.Header {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	for _, name := range names {
+		key.WriteString("\n" + name + ": " + strings.Join(req.Header.Values(name), ", "))
+	}
+	return key.String()
+}
+
+// do joins the in-flight call identical to req, or starts it. The call is
+// cancelled once every caller has given up on it.
+func (c *Coalescer) do(next Doer, req *http.Request) (*http.Response, error) {
+	if (req.Method != http.MethodGet && req.Method != http.MethodHead) || !rewindable(req) {
+		return next.Do(req)
+	}
+
+	key := c.key(req)
+	c.mu.Lock()
+	if c.calls == nil {
+		c.calls = make(map[string]*coalescedCall)
+	}
+	call, ok := c.calls[key]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.WithoutCancel(req.Context()))
+		call = &coalescedCall{done: make(chan struct{}), cancel: cancel}
+		c.calls[key] = call
+		go c.call(next, req.Clone(ctx), key, call)
+	}
+	call.waiters++
+	c.mu.Unlock()
+	if ok {
+		// the body of a request joining a call is never sent
+		closeRequestBody(req)
+	}
+
+	select {
+	case <-call.done:
+	case <-req.Context().Done():
+		c.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			call.cancel()
+			c.forget(key, call)
+		}
+		c.mu.Unlock()
+		return nil, req.Context().Err()
+	}
+	if call.err != nil {
+		return nil, call.err
+	}
+
+	resp := *call.resp
+	resp.Header = call.resp.Header.Clone()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(call.body))
+	resp.Request = req
+	return &resp, nil
+}
+
+// call sends the request of the call and reads its response.
+func (c *Coalescer) call(next Doer, req *http.Request, key string, call *coalescedCall) {
+	defer call.cancel()
+	resp, err := next.Do(req)
+	if err == nil {
+		call.body, err = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.ContentLength = int64(len(call.body))
+	}
+	call.resp, call.err = resp, err
+
+	c.mu.Lock()
+	c.forget(key, call)
+	c.mu.Unlock()
+	close(call.done)
+}
+
+// forget removes the call of key, so later requests start a new one.
+// c.mu must be held.
+func (c *Coalescer) forget(key string, call *coalescedCall) {
+	if c.calls[key] == call {
+		delete(c.calls, key)
+	}
+}
@@ -0,0 +1,137 @@
+package meteor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestService_Coalesce(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		w.Write([]byte(`{"text": "` + r.Header.Get("Accept-Language") + `"}`))
+	}))
+	defer server.Close()
+
+	svc := New().Base(server.URL).Coalesce(NewCoalescer())
+	tests := []struct {
+		name    string
+		lang    string
+		callers int
+	}{
+		{"en", "en", 20},
+		{"fr", "fr", 5},
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan string, 25)
+	for _, tt := range tests {
+		for i := 0; i < tt.callers; i++ {
+			wg.Add(1)
+			go func(lang string) {
+				defer wg.Done()
+				// each caller decodes with its own Responder
+				success := &FakeModel{}
+				if _, err := svc.New().Set("Accept-Language", lang).JSONSuccessResponder(success).Do(); err != nil {
+					t.Errorf("Service.Do() error = %v", err)
+					return
+				}
+				if success.Text != lang {
+					t.Errorf("Service.Do() success = %v, want %v", success.Text, lang)
+				}
+				results <- success.Text
+			}(tt.lang)
+		}
+	}
+
+	// wait for the calls to be in flight
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&requests) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("requests sent = %v, want 2", got)
+	}
+	if got := len(results); got != 25 {
+		t.Errorf("responses = %v, want 25", got)
+	}
+}
+
+func TestCoalescer_credentials(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		w.Write([]byte(`{"text": "` + r.Header.Get("X-Api-Key") + `"}`))
+	}))
+	defer server.Close()
+
+	svc := New().Base(server.URL).Coalesce(NewCoalescer())
+	keys := []string{"key-a", "key-b"}
+	var wg sync.WaitGroup
+	for _, key := range keys {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			success := &FakeModel{}
+			if _, err := svc.New().Set("X-Api-Key", key).JSONSuccessResponder(success).Do(); err != nil {
+				t.Errorf("Service.Do() error = %v", err)
+				return
+			}
+			if success.Text != key {
+				t.Errorf("Service.Do() with %v got the response of %v", key, success.Text)
+			}
+		}(key)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&requests) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("requests sent = %v, want 2", got)
+	}
+}
+
+func TestCoalescer_cancel(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			// the first call is abandoned by every caller
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte(`{"text": "ok"}`))
+	}))
+	defer server.Close()
+
+	svc := New().Base(server.URL).Coalesce(NewCoalescer())
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := svc.New().DoContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Service.DoContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// a later request starts a new call
+	success := &FakeModel{}
+	if _, err := svc.New().JSONSuccessResponder(success).Do(); err != nil || success.Text != "ok" {
+		t.Errorf("Service.Do() = %v, %v", success.Text, err)
+	}
+}
@@ -0,0 +1,109 @@
+package meteor
+
+// StopReason tells why an async batch stopped.
+type StopReason int
+
+const (
+	// NotStopped is the reason of an async batch that is still running.
+	NotStopped StopReason = iota
+	// StoppedAllDone means every job of the batch was done.
+	StoppedAllDone
+	// StoppedFirstSuccess means the batch stopped on its first successful job.
+	StoppedFirstSuccess
+	// StoppedFirstError means the batch stopped on its first failed job.
+	StoppedFirstError
+	// StoppedQuorum means the batch stopped once enough jobs succeeded.
+	StoppedQuorum
+	// StoppedCancelled means the context of the batch was done.
+	StoppedCancelled
+	// StoppedByDoer means an AsyncDoer's ToStop stopped the batch.
+	StoppedByDoer
+	// StoppedNoQuorum means the batch stopped once its quorum could no
+	// longer be reached.
+	StoppedNoQuorum
+)
+
+// String implements the fmt.Stringer interface.
+func (r StopReason) String() string {
+	switch r {
+	case NotStopped:
+		return "notStopped"
+	case StoppedAllDone:
+		return "allDone"
+	case StoppedFirstSuccess:
+		return "firstSuccess"
+	case StoppedFirstError:
+		return "firstError"
+	case StoppedQuorum:
+		return "quorum"
+	case StoppedCancelled:
+		return "cancelled"
+	case StoppedByDoer:
+		return "doer"
+	case StoppedNoQuorum:
+		return "noQuorum"
+	}
+	return "unknown"
+}
+
+// Completion decides when an async batch is complete. Jobs that are not
+// done when the batch completes are cancelled through their context.
+// The zero value waits for every job.
+type Completion struct {
+	reason StopReason
+	quorum int
+}
+
+// WaitAll completes the batch once every job is done.
+func WaitAll() Completion {
+	return Completion{}
+}
+
+// FirstSuccess completes the batch on the first successful job.
+func FirstSuccess() Completion {
+	return Completion{reason: StoppedFirstSuccess}
+}
+
+// FirstError completes the batch on the first failed job (fail-fast).
+func FirstError() Completion {
+	return Completion{reason: StoppedFirstError}
+}
+
+// Quorum completes the batch once n jobs have succeeded, n less than 1
+// meaning 1. The batch stops with StoppedNoQuorum as soon as too few jobs
+// are left to reach n, at once when it has fewer than n jobs.
+func Quorum(n int) Completion {
+	if n < 1 {
+		n = 1
+	}
+	return Completion{reason: StoppedQuorum, quorum: n}
+}
+
+// done tells whether the batch is complete, given the number of jobs that
+// succeeded so far and whether the last job failed.
+func (c Completion) done(succeeded int, lastFailed bool) bool {
+	switch c.reason {
+	case StoppedFirstSuccess:
+		return !lastFailed
+	case StoppedFirstError:
+		return lastFailed
+	case StoppedQuorum:
+		return succeeded >= c.quorum
+	}
+	return false
+}
+
+// unreachable tells whether the quorum of the batch can no longer be
+// reached, given the number of jobs that succeeded and that are pending.
+func (c Completion) unreachable(succeeded, pending int) bool {
+	return c.reason == StoppedQuorum && succeeded+pending < c.quorum
+}
+
+// asyncFailed tells whether the value of a job is a failure, that is a
+// value with a GetError method returning an error.
+func asyncFailed(value interface{}) bool {
+	if v, ok := value.(interface{ GetError() error }); ok {
+		return v.GetError() != nil
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package meteor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// ErrorBodyBytes is the maximum number of bytes of a failure response body
+// kept by an APIError.
+const ErrorBodyBytes = 4096
+
+// APIError is returned by Responders for responses that are not OK. It
+// holds the response status, headers, the start of the raw body and the
+// decoded Failure value, so callers can use errors.As to inspect it.
+type APIError struct {
+	// StatusCode is the response status code.
+	StatusCode int
+	// Header is the response header.
+	Header http.Header
+	// Body holds at most ErrorBodyBytes of the raw response body.
+	Body []byte
+	// Failure is the Responder's decoded failure value, if any.
+	Failure interface{}
+	// Method is the request method.
+	Method string
+	// URL is the request URL with sensitive query parameters redacted.
+	URL string
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("meteor: %s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// IsAPIError reports whether err is an *APIError with one of the given
+// status codes, or any *APIError if no status codes are given.
+func IsAPIError(err error, statusCodes ...int) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if len(statusCodes) == 0 {
+		return true
+	}
+	for _, code := range statusCodes {
+		if apiErr.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// captureErrorBody reads at most ErrorBodyBytes of the response body and
+// restores it, so the body can still be decoded from the start.
+func captureErrorBody(resp *http.Response) []byte {
+	if resp == nil || resp.Body == nil {
+		return nil
+	}
+	raw, _ := ioutil.ReadAll(io.LimitReader(resp.Body, ErrorBodyBytes))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(raw), resp.Body), resp.Body}
+	return raw
+}
+
+// apiErrorOptions are the APIErrors and Redactor settings of the Service
+// doing a call, see APIErrors() and Redactor().
+type apiErrorOptions struct {
+	redactor *Redactor
+	disabled bool
+}
+
+// failureError returns the *APIError of a response that is not OK, or nil
+// if APIErrors are disabled.
+func failureError(opts apiErrorOptions, req *http.Request, resp *http.Response, raw []byte, failure interface{}) error {
+	if opts.disabled {
+		return nil
+	}
+	return newAPIError(opts.redactor, req, resp, raw, failure)
+}
+
+// newAPIError creates an APIError for the request and its response, with
+// the URL redacted by redactor, or by the NewRedactor() defaults if nil.
+func newAPIError(redactor *Redactor, req *http.Request, resp *http.Response, raw []byte, failure interface{}) *APIError {
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       raw,
+		Failure:    failure,
+	}
+	if req == nil {
+		req = resp.Request
+	}
+	if redactor == nil {
+		redactor = defaultRedactor
+	}
+	if req != nil {
+		e.Method = req.Method
+		e.URL = redactor.URL(req.URL)
+	}
+	return e
+}
@@ -0,0 +1,187 @@
+package meteor
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_Do_APIError(t *testing.T) {
+	failBody := `{"errors": [{"code": "EAE:INV-0001","message": "Invalid request"}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "abc")
+		if r.URL.Path == "/ok" {
+			w.Write([]byte(`{"id": 1}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(failBody))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		s           func() (*Service, interface{})
+		path        string
+		wantErr     bool
+		wantFailure bool
+	}{
+		{"json", func() (*Service, interface{}) {
+			fail := newFail()
+			return New().JSONResponder(newSuccess(), fail), fail
+		}, "missing", true, true},
+		{"jsonSuccessOnly", func() (*Service, interface{}) {
+			return New().JSONSuccessResponder(newSuccess()), nil
+		}, "missing", true, false},
+		{"binary", func() (*Service, interface{}) {
+			fail := newFail()
+			return New().BinaryResponder(fail), fail
+		}, "missing", true, true},
+		{"bitset", func() (*Service, interface{}) {
+			fail := newFail()
+			return New().BitsetResponder(fail), fail
+		}, "missing", true, true},
+		{"ok", func() (*Service, interface{}) {
+			return New().JSONResponder(newSuccess(), newFail()), nil
+		}, "ok", false, false},
+		{"disabled", func() (*Service, interface{}) {
+			fail := newFail()
+			return New().JSONResponder(newSuccess(), fail).APIErrors(false), fail
+		}, "missing", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, fail := tt.s()
+			_, err := svc.Base(server.URL).Path(tt.path).QueryStruct(&struct {
+				APIKey string `url:"apiKey"`
+			}{"secret"}).Do()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%v Service.Do() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if tt.wantFailure && fail.(*wxErr).Errors == nil {
+				t.Errorf("%v Service.Do() did not decode the failure", tt.name)
+			}
+			if !tt.wantErr {
+				return
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("%v Service.Do() error = %T, want *APIError", tt.name, err)
+			}
+			if !IsAPIError(err, http.StatusNotFound) || IsAPIError(err, http.StatusUnauthorized) {
+				t.Errorf("%v IsAPIError() did not match the status code %v", tt.name, apiErr.StatusCode)
+			}
+			if apiErr.Header.Get("X-Request-Id") != "abc" {
+				t.Errorf("%v APIError.Header = %v", tt.name, apiErr.Header)
+			}
+			if string(apiErr.Body) != failBody {
+				t.Errorf("%v APIError.Body = %q, want %q", tt.name, apiErr.Body, failBody)
+			}
+			if tt.wantFailure && !assert.Equal(t, fail, apiErr.Failure) {
+				t.Errorf("%v APIError.Failure = %v, want %v", tt.name, apiErr.Failure, fail)
+			}
+			if want := server.URL + "/missing?apiKey=REDACTED"; apiErr.URL != want {
+				t.Errorf("%v APIError.URL = %v, want %v", tt.name, apiErr.URL, want)
+			}
+			if !strings.Contains(err.Error(), "404 Not Found") || strings.Contains(err.Error(), "secret") {
+				t.Errorf("%v APIError.Error() = %v", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestService_Do_APIErrorRedactor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	redactor := &Redactor{QueryParams: []string{"station"}}
+	svc := New().Base(server.URL).Redactor(redactor).QueryStruct(&struct {
+		Station string `url:"station"`
+	}{"KATL"})
+	_, err := svc.New().JSONSuccessResponder(newSuccess()).Do()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Service.Do() error = %v, want *APIError", err)
+	}
+	if want := server.URL + "/?station=REDACTED"; apiErr.URL != want {
+		t.Errorf("APIError.URL = %v, want %v", apiErr.URL, want)
+	}
+}
+
+func TestService_callResponder_APIErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors": [{"code": "EAE:INV-0001","message": "Invalid request"}]}`))
+	}))
+	defer server.Close()
+
+	redactor := &Redactor{QueryParams: []string{"station"}}
+	tests := []struct {
+		name    string
+		s       *Service
+		wantErr bool
+	}{
+		{"enabled", New().Redactor(redactor), true},
+		{"disabled", New().APIErrors(false), false},
+		{"responderFunc", New().Redactor(redactor).ResponderFunc(func() Responder {
+			return JSONResponder(newSuccess(), newFail())
+		}), true},
+		{"responderFuncDisabled", New().APIErrors(false).ResponderFunc(func() Responder {
+			return JSONResponder(newSuccess(), newFail())
+		}), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := tt.s.Base(server.URL).QueryStruct(&struct {
+				Station string `url:"station"`
+			}{"KATL"})
+			if svc.responderFunc == nil {
+				svc.JSONResponder(newSuccess(), newFail())
+			}
+			req, err := svc.Request()
+			if err != nil {
+				t.Fatalf("%v Service.Request() error = %v", tt.name, err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("%v http.Client.Do() error = %v", tt.name, err)
+			}
+			defer resp.Body.Close()
+
+			r := svc.callResponder()
+			_, err = r.Respond(req, resp, nil).DoResponse()
+			if IsAPIError(err) != tt.wantErr || (err != nil) != tt.wantErr {
+				t.Fatalf("%v Responder.DoResponse() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if r.GetFailure().(*wxErr).Errors == nil {
+				t.Errorf("%v Responder.DoResponse() did not decode the failure", tt.name)
+			}
+			if err != nil && strings.Contains(err.Error(), "KATL") {
+				t.Errorf("%v APIError.Error() = %v, want the station redacted", tt.name, err)
+			}
+		})
+	}
+}
+
+func Test_captureErrorBody(t *testing.T) {
+	body := strings.Repeat("a", ErrorBodyBytes+10)
+	resp := &http.Response{Body: ioutil.NopCloser(strings.NewReader(body))}
+
+	raw := captureErrorBody(resp)
+	if len(raw) != ErrorBodyBytes {
+		t.Errorf("captureErrorBody() len = %v, want %v", len(raw), ErrorBodyBytes)
+	}
+	rest := &strings.Builder{}
+	if _, err := io.Copy(rest, resp.Body); err != nil || rest.String() != body {
+		t.Errorf("captureErrorBody() did not restore the body, got len %v, err %v", rest.Len(), err)
+	}
+}
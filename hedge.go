@@ -0,0 +1,188 @@
+package meteor
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+)
+
+// HedgePolicy determines when a duplicate (hedge) of a slow request is sent.
+// Only GET and HEAD requests are hedged, as they are idempotent.
+type HedgePolicy struct {
+	// Delay is the time to wait for the first response before the hedge
+	// is sent. A value of 0 or less disables hedging.
+	Delay time.Duration
+
+	// AlternateBase is the base URL hedges are sent to. Only its scheme and
+	// host replace the ones of the request. If empty, hedges are sent to the
+	// request's URL.
+	AlternateBase string
+
+	// Metrics records how often hedges are sent and win. If nil, nothing
+	// is recorded.
+	Metrics *HedgeMetrics
+}
+
+// NewHedgePolicy returns a HedgePolicy sending a hedge after delay, to the
+// alternate base URL if one is given, with new HedgeMetrics.
+func NewHedgePolicy(delay time.Duration, alternateBase ...string) *HedgePolicy {
+	policy := &HedgePolicy{
+		Delay:   delay,
+		Metrics: &HedgeMetrics{},
+	}
+	if len(alternateBase) > 0 {
+		policy.AlternateBase = alternateBase[0]
+	}
+	return policy
+}
+
+// HedgeMetrics counts the hedged requests of a HedgePolicy. It is safe for
+// concurrent use.
+type HedgeMetrics struct {
+	requests int64
+	hedges   int64
+	wins     int64
+}
+
+// Requests is the number of requests that could be hedged.
+func (m *HedgeMetrics) Requests() int64 {
+	return atomic.LoadInt64(&m.requests)
+}
+
+// Hedges is the number of hedges sent.
+func (m *HedgeMetrics) Hedges() int64 {
+	return atomic.LoadInt64(&m.hedges)
+}
+
+// Wins is the number of hedges that answered before the first request.
+func (m *HedgeMetrics) Wins() int64 {
+	return atomic.LoadInt64(&m.wins)
+}
+
+// hedgeable reports whether the request may be hedged by the policy.
+func (p *HedgePolicy) hedgeable(req *http.Request) bool {
+	return p.Delay > 0 && (req.Method == http.MethodGet || req.Method == http.MethodHead) && rewindable(req)
+}
+
+// hedgeRequest returns a copy of the request for the hedge, sent with ctx.
+func (p *HedgePolicy) hedgeRequest(ctx context.Context, req *http.Request) (*http.Request, error) {
+	r, err := rewindBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if r == req {
+		r = req.Clone(ctx)
+	} else {
+		r = r.WithContext(ctx)
+	}
+	if p.AlternateBase != "" {
+		base, err := url.Parse(p.AlternateBase)
+		if err != nil {
+			return nil, err
+		}
+		r.URL.Scheme, r.URL.Host, r.Host = base.Scheme, base.Host, ""
+	}
+	return r, nil
+}
+
+// hedgeResult is the outcome of a request sent by a hedgeDoer, the
+// first request having index 0 and the hedge index 1.
+type hedgeResult struct {
+	index int
+	resp  *http.Response
+	err   error
+}
+
+// cancelBody cancels the context of its request once closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the context of its request.
+func (b cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// hedgeDoer sends requests with the given Doer following a HedgePolicy.
+type hedgeDoer struct {
+	doer   Doer
+	policy *HedgePolicy
+}
+
+// Do sends the request and, if it has not answered within the policy's
+// delay, a hedge. The first response wins; the other request is cancelled
+// and its body drained. Errors are only returned once both requests failed.
+// Implements Doer interface
+func (d hedgeDoer) Do(req *http.Request) (*http.Response, error) {
+	if !d.policy.hedgeable(req) {
+		return d.doer.Do(req)
+	}
+	metrics := d.policy.Metrics
+	if metrics == nil {
+		metrics = &HedgeMetrics{}
+	}
+	atomic.AddInt64(&metrics.requests, 1)
+
+	results := make(chan hedgeResult, 2)
+	send := func(index int, r *http.Request) {
+		resp, err := d.doer.Do(r)
+		results <- hedgeResult{index, resp, err}
+	}
+
+	var cancels [2]context.CancelFunc
+	ctx, cancel := context.WithCancel(req.Context())
+	cancels[0] = cancel
+	go send(0, req.WithContext(ctx))
+	pending := 1
+
+	timer := time.NewTimer(d.policy.Delay)
+	defer timer.Stop()
+	var last hedgeResult
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			ctx, cancel := context.WithCancel(req.Context())
+			hedge, err := d.policy.hedgeRequest(ctx, req)
+			if err != nil {
+				cancel()
+				continue
+			}
+			cancels[1] = cancel
+			atomic.AddInt64(&metrics.hedges, 1)
+			go send(1, hedge)
+			pending++
+		case last = <-results:
+			pending--
+			if last.err != nil {
+				cancels[last.index]()
+				continue
+			}
+			if pending > 0 {
+				cancels[1-last.index]()
+				go drainHedge(results)
+			}
+			if last.index == 1 {
+				atomic.AddInt64(&metrics.wins, 1)
+			}
+			last.resp.Body = cancelBody{last.resp.Body, cancels[last.index]}
+			return last.resp, nil
+		}
+	}
+	return nil, last.err
+}
+
+// drainHedge drains the response body of the losing request.
+func drainHedge(results chan hedgeResult) {
+	r := <-results
+	if r.resp != nil {
+		io.CopyN(ioutil.Discard, r.resp.Body, NBytes)
+		r.resp.Body.Close()
+	}
+}
@@ -0,0 +1,115 @@
+package meteor
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestService_Hedge(t *testing.T) {
+	policy := NewHedgePolicy(time.Millisecond)
+	tests := []struct {
+		name string
+		s    *Service
+		want *HedgePolicy
+	}{
+		{"default", New(), nil},
+		{"policy", New().Hedge(policy), policy},
+		{"policyNew", New().Hedge(policy).New(), policy},
+		{"policyReset", New().Hedge(policy).Reset(), nil},
+		{"policyNil", New().Hedge(policy).Hedge(nil), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.hedgePolicy; got != tt.want {
+				t.Errorf("%v Service.Hedge() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_hedgeDoer_Do(t *testing.T) {
+	cancelled := make(chan struct{}, 2)
+	newServer := func(name string, delay time.Duration) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+				cancelled <- struct{}{}
+				return
+			case <-time.After(delay):
+			}
+			w.Write([]byte(name))
+		}))
+	}
+	slow := newServer("slow", 2*time.Second)
+	defer slow.Close()
+	fast := newServer("fast", 0)
+	defer fast.Close()
+	// the first request to flaky is slow, the following ones are fast
+	var flakyRequests int32
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&flakyRequests, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+				cancelled <- struct{}{}
+				return
+			case <-time.After(2 * time.Second):
+			}
+		}
+		w.Write([]byte("flaky"))
+	}))
+	defer flaky.Close()
+
+	tests := []struct {
+		name          string
+		base          string
+		method        string
+		alternateBase string
+		want          string
+		wantHedges    int64
+		wantWins      int64
+		wantCancelled bool
+	}{
+		{"fast", fast.URL, "GET", slow.URL, "fast", 0, 0, false},
+		{"slowAlternate", slow.URL, "GET", fast.URL, "fast", 1, 1, true},
+		{"sameURL", flaky.URL, "GET", "", "flaky", 1, 1, true},
+		{"post", fast.URL, "POST", slow.URL, "fast", 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := NewHedgePolicy(20*time.Millisecond, tt.alternateBase)
+			svc := New().Base(tt.base).Method(tt.method).Hedge(policy)
+			req, err := svc.Request()
+			if err != nil {
+				t.Fatalf("%v Service.Request() error = %v", tt.name, err)
+			}
+
+			// send with the Service's Doer to read the winning body
+			resp, err := svc.doer().Do(req)
+			if err != nil {
+				t.Fatalf("%v hedgeDoer.Do() error = %v", tt.name, err)
+			}
+			body, _ := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			if string(body) != tt.want {
+				t.Errorf("%v hedgeDoer.Do() body = %q, want %q", tt.name, body, tt.want)
+			}
+			if got := policy.Metrics.Hedges(); got != tt.wantHedges {
+				t.Errorf("%v HedgeMetrics.Hedges() = %v, want %v", tt.name, got, tt.wantHedges)
+			}
+			if got := policy.Metrics.Wins(); got != tt.wantWins {
+				t.Errorf("%v HedgeMetrics.Wins() = %v, want %v", tt.name, got, tt.wantWins)
+			}
+			if tt.wantCancelled {
+				select {
+				case <-cancelled:
+				case <-time.After(time.Second):
+					t.Errorf("%v the losing request was not cancelled", tt.name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package meteor
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// redacted replaces the values of sensitive query parameters and headers.
+const redacted = "REDACTED"
+
+// Logger logs structured events. It is implemented by *slog.Logger.
+type Logger interface {
+	Log(ctx context.Context, level slog.Level, msg string, args ...interface{})
+}
+
+// LogLevels are the levels of the events logged by a Service.
+type LogLevels struct {
+	// Request is the level of the event logged before sending a request.
+	Request slog.Level
+	// Response is the level of the event logged after receiving a response.
+	Response slog.Level
+	// Error is the level of the event logged when sending a request fails.
+	Error slog.Level
+}
+
+// DefaultLogLevels logs requests and responses at debug level and errors at error level.
+var DefaultLogLevels = LogLevels{
+	Request:  slog.LevelDebug,
+	Response: slog.LevelDebug,
+	Error:    slog.LevelError,
+}
+
+// Redactor hides the values of sensitive query parameters and headers
+// in logs and errors. Names are matched case-insensitively.
+type Redactor struct {
+	QueryParams []string
+	Headers     []string
+}
+
+// NewRedactor returns a Redactor for common API key, token and
+// authorization query parameters and headers.
+func NewRedactor() *Redactor {
+	return &Redactor{
+		QueryParams: []string{"apiKey", "api_key", "api", "key", "token", "access_token", "client_secret", "password", "secret", "signature"},
+		Headers:     []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"},
+	}
+}
+
+// defaultRedactor is used when a Service has no Redactor.
+var defaultRedactor = NewRedactor()
+
+// URL returns the URL string with the values of sensitive query parameters redacted.
+func (r *Redactor) URL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	if u.RawQuery == "" {
+		return u.String()
+	}
+	values, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		ru := *u
+		ru.RawQuery = redacted
+		return ru.String()
+	}
+	for key := range values {
+		if contains(r.QueryParams, key) {
+			values[key] = []string{redacted}
+		}
+	}
+	ru := *u
+	ru.RawQuery = values.Encode()
+	return ru.String()
+}
+
+// Header returns a copy of the header with the values of sensitive headers redacted.
+func (r *Redactor) Header(header http.Header) http.Header {
+	h := make(http.Header, len(header))
+	for key, values := range header {
+		if contains(r.Headers, key) {
+			h[key] = []string{redacted}
+		} else {
+			h[key] = values
+		}
+	}
+	return h
+}
+
+// Error returns the error message with the URL of a *url.Error redacted.
+func (r *Redactor) Error(err error) string {
+	if err == nil {
+		return ""
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if u, perr := url.Parse(urlErr.URL); perr == nil {
+			return strings.Replace(err.Error(), urlErr.URL, r.URL(u), -1)
+		}
+	}
+	return err.Error()
+}
+
+// contains reports whether name is in names, ignoring case.
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// logRequest logs the request event of a Service.
+func (s *Service) logRequest(req *http.Request) {
+	if s.logger == nil {
+		return
+	}
+	s.logger.Log(req.Context(), s.getLogLevels().Request, "meteor request",
+		"method", req.Method,
+		"url", s.getRedactor().URL(req.URL),
+		"header", s.getRedactor().Header(req.Header),
+	)
+}
+
+// logResponse logs the response or error event of a Service.
+func (s *Service) logResponse(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+	if s.logger == nil {
+		return
+	}
+	redactor := s.getRedactor()
+	if err != nil {
+		s.logger.Log(req.Context(), s.getLogLevels().Error, "meteor request failed",
+			"method", req.Method,
+			"url", redactor.URL(req.URL),
+			"duration", elapsed,
+			"error", redactor.Error(err),
+		)
+		return
+	}
+	s.logger.Log(req.Context(), s.getLogLevels().Response, "meteor response",
+		"method", req.Method,
+		"url", redactor.URL(req.URL),
+		"status", resp.StatusCode,
+		"header", redactor.Header(resp.Header),
+		"duration", elapsed,
+	)
+}
@@ -0,0 +1,106 @@
+package meteor
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRedactor_URL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"noQuery", "https://example.com/foo", "https://example.com/foo"},
+		{"apiKey", "https://example.com/foo?apiKey=abc&units=e", "https://example.com/foo?apiKey=REDACTED&units=e"},
+		{"caseInsensitive", "https://example.com/foo?APIKEY=abc", "https://example.com/foo?APIKEY=REDACTED"},
+		{"multiple", "https://example.com/foo?token=a&token=b", "https://example.com/foo?token=REDACTED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _ := url.Parse(tt.url)
+			if got := NewRedactor().URL(u); got != tt.want {
+				t.Errorf("%v Redactor.URL() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactor_Header(t *testing.T) {
+	header := http.Header{"Authorization": []string{"Basic abc"}, "Accept": []string{jsonContentType}}
+	got := NewRedactor().Header(header)
+	if got.Get("Authorization") != redacted || got.Get("Accept") != jsonContentType {
+		t.Errorf("Redactor.Header() = %v", got)
+	}
+	if header.Get("Authorization") != "Basic abc" {
+		t.Errorf("Redactor.Header() modified the original header = %v", header)
+	}
+}
+
+func TestRedactor_Error(t *testing.T) {
+	err := &url.Error{Op: "Get", URL: "https://example.com/foo?apiKey=abc", Err: errors.New("boom")}
+	if got, want := NewRedactor().Error(err), `Get "https://example.com/foo?apiKey=REDACTED": boom`; got != want {
+		t.Errorf("Redactor.Error() = %v, want %v", got, want)
+	}
+}
+
+func TestService_Logger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name  string
+		s     func(Logger) *Service
+		level slog.Level
+		want  []string
+		skip  []string
+	}{
+		{"default", func(l Logger) *Service {
+			return New().Logger(l)
+		}, slog.LevelDebug, []string{"meteor request", "meteor response", "status=418", "apiKey=REDACTED", "Authorization:[REDACTED]"}, []string{"secret"}},
+		{"levels", func(l Logger) *Service {
+			return New().Logger(l).LogLevels(LogLevels{Request: slog.LevelDebug, Response: slog.LevelInfo, Error: slog.LevelError})
+		}, slog.LevelInfo, []string{"meteor response"}, []string{"meteor request"}},
+		{"redactor", func(l Logger) *Service {
+			return New().Logger(l).Redactor(&Redactor{QueryParams: []string{"units"}})
+		}, slog.LevelDebug, []string{"units=REDACTED", "apiKey=secret"}, nil},
+		{"new", func(l Logger) *Service {
+			return New().Logger(l).New()
+		}, slog.LevelDebug, []string{"meteor request"}, nil},
+		{"nil", func(l Logger) *Service {
+			return New().Logger(l).Logger(nil)
+		}, slog.LevelDebug, nil, []string{"meteor"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			logger := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: tt.level}))
+			svc := tt.s(logger).Base(server.URL).Set("Authorization", "secret").QueryStruct(&struct {
+				APIKey string `url:"apiKey"`
+				Units  string `url:"units"`
+			}{"secret", "e"})
+			if _, err := svc.Do(); err != nil {
+				t.Fatalf("%v Service.Do() error = %v", tt.name, err)
+			}
+			got := buf.String()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("%v log = %q, want it to contain %q", tt.name, got, want)
+				}
+			}
+			for _, skip := range tt.skip {
+				if strings.Contains(got, skip) {
+					t.Errorf("%v log = %q, want it not to contain %q", tt.name, got, skip)
+				}
+			}
+		})
+	}
+}
@@ -35,6 +35,12 @@ type Meteor struct {
 
 	// User agent used when communicating with the API.
 	UserAgent string
+
+	// Logger used by the Common service and services created from it.
+	logger Logger
+
+	// RateLimiter used by the Common service and services created from it.
+	rateLimiter *RateLimiter
 }
 
 // GetCredBy gets a credential by key.
@@ -50,6 +56,37 @@ func (c *Meteor) GetHTTPClient() *http.Client {
 	return c.httpClient
 }
 
+// SetLogger sets the Logger on the Meteor and its Common service, so that
+// services created with Common.New() log with it.
+func (c *Meteor) SetLogger(logger Logger) *Meteor {
+	c.logger = logger
+	if c.Common != nil {
+		c.Common.Logger(logger)
+	}
+	return c
+}
+
+// GetLogger gets the Logger.
+func (c *Meteor) GetLogger() Logger {
+	return c.logger
+}
+
+// SetRateLimiter sets the RateLimiter on the Meteor and its Common service,
+// so that services created with Common.New() wait on it. Requests use the
+// bucket of the credential name if one is given, or of their host.
+func (c *Meteor) SetRateLimiter(limiter *RateLimiter, credential ...string) *Meteor {
+	c.rateLimiter = limiter
+	if c.Common != nil {
+		c.Common.RateLimit(limiter, credential...)
+	}
+	return c
+}
+
+// GetRateLimiter gets the RateLimiter.
+func (c *Meteor) GetRateLimiter() *RateLimiter {
+	return c.rateLimiter
+}
+
 // NewClient returns a new API client. If a nil httpClient is
 // provided, http.DefaultClient will be used. To use API methods which require
 // authentication, provide an http.Client that will perform the authentication
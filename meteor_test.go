@@ -1,6 +1,7 @@
 package meteor
 
 import (
+	"log/slog"
 	"net"
 	"net/http"
 	"reflect"
@@ -90,6 +91,29 @@ func TestMeteor_GetHTTPClient(t *testing.T) {
 	}
 }
 
+func TestMeteor_SetLogger(t *testing.T) {
+	logger := slog.Default()
+	c := NewMeteor(credentials).SetLogger(logger)
+	if got := c.GetLogger(); got != logger {
+		t.Errorf("Meteor.GetLogger() = %v, want %v", got, logger)
+	}
+	if got := c.Common.New().logger; got != logger {
+		t.Errorf("Meteor.Common.New().logger = %v, want %v", got, logger)
+	}
+}
+
+func TestMeteor_SetRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(10, 1)
+	c := NewMeteor(credentials).SetRateLimiter(limiter, "apiKey")
+	if got := c.GetRateLimiter(); got != limiter {
+		t.Errorf("Meteor.GetRateLimiter() = %v, want %v", got, limiter)
+	}
+	svc := c.Common.New()
+	if svc.rateLimiter != limiter || svc.rateLimitKey != "apiKey" {
+		t.Errorf("Meteor.Common.New() rate limiter = %v, key %v", svc.rateLimiter, svc.rateLimitKey)
+	}
+}
+
 func TestNewMeteor(t *testing.T) {
 	type args struct {
 		credentials Credentials
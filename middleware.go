@@ -0,0 +1,81 @@
+package meteor
+
+import (
+	"net/http"
+	"time"
+)
+
+// Middleware wraps a Doer with client-side behavior, such as logging or
+// adding headers, and returns the wrapped Doer.
+type Middleware func(Doer) Doer
+
+// DoerFunc is an adapter to allow the use of ordinary functions as Doers.
+type DoerFunc func(*http.Request) (*http.Response, error)
+
+// Do calls f(req).
+// Implements Doer interface
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Chain composes the middleware around the doer. The first middleware is the
+// outermost one, so it sees the request first and the response last.
+func Chain(doer Doer, middleware ...Middleware) Doer {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		doer = middleware[i](doer)
+	}
+	return doer
+}
+
+// closeRequestBody closes the body of a request that is not sent, as an
+// http.RoundTripper does, so body writers and open files are released.
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// LoggingMiddleware logs the method, URL, status and duration of every request
+// with the given printf-style function, e.g. log.Printf. Sensitive query
+// parameters are redacted with the NewRedactor() defaults.
+func LoggingMiddleware(printf func(format string, v ...interface{})) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.Do(req)
+			if err != nil {
+				printf("%s %s error=%v duration=%v", req.Method, defaultRedactor.URL(req.URL), defaultRedactor.Error(err), time.Since(start))
+				return resp, err
+			}
+			printf("%s %s status=%d duration=%v", req.Method, defaultRedactor.URL(req.URL), resp.StatusCode, time.Since(start))
+			return resp, err
+		})
+	}
+}
+
+// HeaderMiddleware sets the given headers on every request, replacing
+// existing values. The original request is not modified.
+func HeaderMiddleware(header http.Header) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			r := req.Clone(req.Context())
+			for key, values := range header {
+				r.Header[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+			}
+			return next.Do(r)
+		})
+	}
+}
+
+// TimingMiddleware calls fn with the request, the response, the error and
+// the time it took the wrapped Doer to respond.
+func TimingMiddleware(fn func(*http.Request, *http.Response, error, time.Duration)) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.Do(req)
+			fn(req, resp, err, time.Since(start))
+			return resp, err
+		})
+	}
+}
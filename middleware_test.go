@@ -0,0 +1,134 @@
+package meteor
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingMiddleware appends its name to calls before and after the request.
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			*calls = append(*calls, name+">")
+			resp, err := next.Do(req)
+			*calls = append(*calls, "<"+name)
+			return resp, err
+		})
+	}
+}
+
+func TestService_Use(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	var calls []string
+	parent := New().Base(server.URL).Use(recordingMiddleware("a", &calls), nil)
+	child := parent.New().Use(recordingMiddleware("b", &calls))
+
+	tests := []struct {
+		name string
+		s    *Service
+		want []string
+	}{
+		{"parent", parent, []string{"a>", "<a"}},
+		{"child", child, []string{"a>", "b>", "<b", "<a"}},
+		{"reset", child.New().Reset().Base(server.URL), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+			if _, err := tt.s.Do(); err != nil {
+				t.Fatalf("%v Service.Do() error = %v", tt.name, err)
+			}
+			if fmt.Sprint(calls) != fmt.Sprint(tt.want) {
+				t.Errorf("%v middleware calls = %v, want %v", tt.name, calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_Use_retry(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	var calls []string
+	if _, err := New().Base(server.URL).Use(recordingMiddleware("a", &calls)).Retry(newTestRetryPolicy(2)).Do(); err != nil {
+		t.Fatalf("Service.Do() error = %v", err)
+	}
+	if want := []string{"a>", "<a", "a>", "<a"}; fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Errorf("middleware calls = %v, want %v", calls, want)
+	}
+}
+
+func TestHeaderMiddleware(t *testing.T) {
+	var got http.Header
+	doer := Chain(DoerFunc(func(req *http.Request) (*http.Response, error) {
+		got = req.Header
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}), HeaderMiddleware(http.Header{"x-api-key": []string{"secret"}, "Accept": []string{jsonContentType}}))
+
+	req, _ := http.NewRequest("GET", baseURL, nil)
+	req.Header.Set("Accept", textContentType)
+	doer.Do(req)
+
+	if got.Get("X-Api-Key") != "secret" || got.Get("Accept") != jsonContentType {
+		t.Errorf("HeaderMiddleware() header = %v", got)
+	}
+	if req.Header.Get("X-Api-Key") != "" || req.Header.Get("Accept") != textContentType {
+		t.Errorf("HeaderMiddleware() modified the original request header = %v", req.Header)
+	}
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	var lines []string
+	printf := func(format string, v ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, v...))
+	}
+	doer := Chain(DoerFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method == "DELETE" {
+			return nil, fmt.Errorf("boom")
+		}
+		return &http.Response{StatusCode: http.StatusTeapot}, nil
+	}), LoggingMiddleware(printf))
+
+	req, _ := http.NewRequest("GET", baseURL, nil)
+	doer.Do(req)
+	req, _ = http.NewRequest("DELETE", baseURL, nil)
+	doer.Do(req)
+
+	if len(lines) != 2 {
+		t.Fatalf("LoggingMiddleware() lines = %v, want 2", lines)
+	}
+	if !strings.HasPrefix(lines[0], "GET https://example.com status=418") {
+		t.Errorf("LoggingMiddleware() line = %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "DELETE https://example.com error=boom") {
+		t.Errorf("LoggingMiddleware() line = %q", lines[1])
+	}
+}
+
+func TestTimingMiddleware(t *testing.T) {
+	var elapsed time.Duration
+	doer := Chain(DoerFunc(func(req *http.Request) (*http.Response, error) {
+		time.Sleep(10 * time.Millisecond)
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}), TimingMiddleware(func(req *http.Request, resp *http.Response, err error, d time.Duration) {
+		elapsed = d
+	}))
+
+	req, _ := http.NewRequest("GET", baseURL, nil)
+	doer.Do(req)
+	if elapsed < 10*time.Millisecond {
+		t.Errorf("TimingMiddleware() elapsed = %v, want >= %v", elapsed, 10*time.Millisecond)
+	}
+}
@@ -0,0 +1,383 @@
+package meteor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxPages is the default number of pages Pages requests.
+const DefaultMaxPages = 100
+
+// ErrMaxPages is returned, wrapped with the number of pages, when Pages
+// stops at the Service's MaxPages while there are more pages.
+var ErrMaxPages = errors.New("meteor: too many pages")
+
+// Page is a page of a paginated response.
+type Page struct {
+	// Number is the number of the page, starting at 1.
+	Number int
+	// Request is the request of the page.
+	Request *http.Request
+	// Response is the response of the page. Its body is already read.
+	Response *http.Response
+	// Body is the body of the response.
+	Body []byte
+	// Responder is the Responder that decoded the page.
+	Responder Responder
+}
+
+// Paginator gets the request of the next page of a paginated response.
+type Paginator interface {
+	// Next returns the request of the page after page, or nil if page is
+	// the last one.
+	Next(page *Page) (*http.Request, error)
+}
+
+// PaginatorFunc is an adapter to allow the use of ordinary functions as
+// Paginators.
+type PaginatorFunc func(page *Page) (*http.Request, error)
+
+// Next calls f(page).
+// Implements Paginator interface
+func (f PaginatorFunc) Next(page *Page) (*http.Request, error) {
+	return f(page)
+}
+
+// LinkPaginator follows the rel="next" URL of the Link header (RFC 8288).
+// The Authorization and Cookie headers are not sent to a next URL on another
+// scheme or host.
+type LinkPaginator struct{}
+
+// NewLinkPaginator returns a LinkPaginator.
+func NewLinkPaginator() *LinkPaginator {
+	return &LinkPaginator{}
+}
+
+// Next returns a request to the next link of the page, if any.
+// Implements Paginator interface
+func (p *LinkPaginator) Next(page *Page) (*http.Request, error) {
+	link := nextLink(page.Response.Header)
+	if link == "" {
+		return nil, nil
+	}
+	u, err := page.Request.URL.Parse(link)
+	if err != nil {
+		return nil, err
+	}
+	return nextPageRequest(page.Request, u)
+}
+
+// nextLink gets the target of the rel="next" link of the Link header.
+func nextLink(header http.Header) string {
+	for _, value := range header.Values("Link") {
+		for _, link := range strings.Split(value, ",") {
+			target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+			if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range strings.Split(params, ";") {
+				name, rel, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(name, "rel") {
+					continue
+				}
+				for _, r := range strings.Fields(strings.Trim(rel, `"`)) {
+					if strings.EqualFold(r, "next") {
+						return strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// CursorPaginator sets the Param query parameter of the next page to the
+// cursor found in the JSON body at Path, e.g. "meta.next_cursor". There is
+// no next page when the cursor is missing, null or empty.
+type CursorPaginator struct {
+	// Path is the dot separated path of the cursor in the JSON body.
+	Path string
+	// Param is the query parameter of the cursor.
+	Param string
+}
+
+// NewCursorPaginator returns a CursorPaginator.
+func NewCursorPaginator(path, param string) *CursorPaginator {
+	return &CursorPaginator{Path: path, Param: param}
+}
+
+// Next returns a request with the cursor of the page, if any.
+// Implements Paginator interface
+func (p *CursorPaginator) Next(page *Page) (*http.Request, error) {
+	cursor, err := jsonPathValue(page.Body, p.Path)
+	if err != nil || cursor == nil {
+		return nil, err
+	}
+	value := fmt.Sprint(cursor)
+	if n, ok := cursor.(json.Number); ok {
+		value = n.String()
+	}
+	if value == "" {
+		return nil, nil
+	}
+	return setPageQuery(page.Request, p.Param, value)
+}
+
+// OffsetPaginator moves the OffsetParam query parameter of the next page by
+// Limit, also setting LimitParam if given. There is no next page when the
+// page has fewer than Limit items in the JSON array at ItemsPath, or in the
+// top-level array if ItemsPath is empty.
+type OffsetPaginator struct {
+	// OffsetParam is the query parameter of the offset, 0 if missing.
+	OffsetParam string
+	// LimitParam is the query parameter of the limit, if any.
+	LimitParam string
+	// Limit is the number of items of a page.
+	Limit int
+	// ItemsPath is the dot separated path of the items in the JSON body.
+	ItemsPath string
+}
+
+// NewOffsetPaginator returns an OffsetPaginator for pages of limit items
+// in the JSON array at itemsPath.
+func NewOffsetPaginator(offsetParam, limitParam string, limit int, itemsPath string) *OffsetPaginator {
+	return &OffsetPaginator{OffsetParam: offsetParam, LimitParam: limitParam, Limit: limit, ItemsPath: itemsPath}
+}
+
+// Next returns a request with the offset of the next page, if any.
+// Implements Paginator interface
+func (p *OffsetPaginator) Next(page *Page) (*http.Request, error) {
+	if p.Limit <= 0 {
+		return nil, fmt.Errorf("meteor: invalid page limit %d", p.Limit)
+	}
+	count, err := jsonItemCount(page.Body, p.ItemsPath)
+	if err != nil || count < p.Limit {
+		return nil, err
+	}
+	offset, err := pageQueryInt(page.Request, p.OffsetParam, 0)
+	if err != nil {
+		return nil, err
+	}
+	req, err := setPageQuery(page.Request, p.OffsetParam, strconv.Itoa(offset+p.Limit))
+	if err != nil || p.LimitParam == "" {
+		return req, err
+	}
+	return setPageQuery(req, p.LimitParam, strconv.Itoa(p.Limit))
+}
+
+// PageNumberPaginator increments the Param query parameter of the next
+// page, starting from Start if it is missing. There is no next page when
+// the page has no items in the JSON array at ItemsPath, or in the top-level
+// array if ItemsPath is empty.
+type PageNumberPaginator struct {
+	// Param is the query parameter of the page number.
+	Param string
+	// Start is the number of the first page, used when Param is missing.
+	Start int
+	// ItemsPath is the dot separated path of the items in the JSON body.
+	ItemsPath string
+}
+
+// NewPageNumberPaginator returns a PageNumberPaginator starting at page 1.
+func NewPageNumberPaginator(param, itemsPath string) *PageNumberPaginator {
+	return &PageNumberPaginator{Param: param, Start: 1, ItemsPath: itemsPath}
+}
+
+// Next returns a request with the number of the next page, if any.
+// Implements Paginator interface
+func (p *PageNumberPaginator) Next(page *Page) (*http.Request, error) {
+	count, err := jsonItemCount(page.Body, p.ItemsPath)
+	if err != nil || count == 0 {
+		return nil, err
+	}
+	number, err := pageQueryInt(page.Request, p.Param, p.Start)
+	if err != nil {
+		return nil, err
+	}
+	return setPageQuery(page.Request, p.Param, strconv.Itoa(number+1))
+}
+
+// crossOriginHeaders are dropped from a next page request to another origin,
+// as http.Client does on redirects, so that a Link header cannot leak the
+// credentials of the Service.
+var crossOriginHeaders = []string{"Authorization", "Cookie"}
+
+// nextPageRequest returns a copy of req, with a fresh body, for the URL u.
+// The credentials of req are dropped when u has another scheme or host.
+func nextPageRequest(req *http.Request, u *url.URL) (*http.Request, error) {
+	if !rewindable(req) {
+		return nil, errors.New("meteor: the request body of the next page cannot be rewound")
+	}
+	next, err := rewindBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if next == req {
+		next = req.Clone(req.Context())
+	}
+	if !strings.EqualFold(u.Scheme, req.URL.Scheme) || !strings.EqualFold(u.Host, req.URL.Host) {
+		for _, key := range crossOriginHeaders {
+			next.Header.Del(key)
+		}
+	}
+	next.URL = u
+	next.Host = u.Host
+	return next, nil
+}
+
+// setPageQuery returns a copy of req with the query parameter key set to
+// value, keeping the order of the other parameters.
+func setPageQuery(req *http.Request, key, value string) (*http.Request, error) {
+	params, err := parseQueryParams(req.URL.RawQuery)
+	if err != nil {
+		return nil, err
+	}
+	u := *req.URL
+	u.RawQuery = params.set(key, value).encode(true, false)
+	return nextPageRequest(req, &u)
+}
+
+// pageQueryInt gets the integer query parameter key of req, or def if it is
+// missing.
+func pageQueryInt(req *http.Request, key string, def int) (int, error) {
+	value := req.URL.Query().Get(key)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("meteor: invalid page query %s=%q", key, value)
+	}
+	return n, nil
+}
+
+// jsonPathValue gets the value at the dot separated path of the JSON body.
+// Path elements index objects by key and arrays by number. A missing value
+// is nil.
+func jsonPathValue(body []byte, path string) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return value, nil
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value = v[key]
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, nil
+			}
+			value = v[i]
+		default:
+			return nil, nil
+		}
+	}
+	return value, nil
+}
+
+// jsonItemCount counts the items of the JSON array at the path of the body.
+func jsonItemCount(body []byte, path string) (int, error) {
+	value, err := jsonPathValue(body, path)
+	if err != nil {
+		return 0, err
+	}
+	if value == nil {
+		return 0, nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return 0, fmt.Errorf("meteor: page items at %q are not an array", path)
+	}
+	return len(items), nil
+}
+
+// Pages returns an iterator over the pages of the Service's request. Each
+// page is decoded by a Responder, a fresh one if the Service has a
+// ResponderFunc, and the Service's Paginator (see Paginate()) finds the
+// request of the next page. Without a Paginator, only the first page is
+// requested. Any error, such as an *APIError, is yielded last, and
+// ErrMaxPages is yielded if there are more pages than MaxPages.
+//
+// With the Service's responder, every page is decoded into the same success
+// value, so use each page before asking for the next.
+func (s *Service) Pages(ctx context.Context) iter.Seq2[*Page, error] {
+	return func(yield func(*Page, error) bool) {
+		req, err := s.RequestWithContext(ctx)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		maxPages := s.maxPages
+		if maxPages <= 0 {
+			maxPages = DefaultMaxPages
+		}
+
+		for number := 1; ; number++ {
+			page, err := s.page(number, req)
+			if err != nil {
+				yield(page, err)
+				return
+			}
+			if !yield(page, nil) || s.paginator == nil {
+				return
+			}
+			if req, err = s.paginator.Next(page); err != nil || req == nil {
+				if err != nil {
+					yield(nil, err)
+				}
+				return
+			}
+			if number == maxPages {
+				yield(nil, fmt.Errorf("%w: %d", ErrMaxPages, maxPages))
+				return
+			}
+		}
+	}
+}
+
+// page sends the request of a page and decodes it.
+func (s *Service) page(number int, req *http.Request) (*Page, error) {
+	page := &Page{Number: number, Request: req}
+	responder := &pageResponder{Responder: s.callResponder(), page: page}
+	_, err := s.do(req, responder)
+	page.Responder = responder.Responder
+	if responder.err != nil {
+		return page, responder.err
+	}
+	return page, err
+}
+
+// pageResponder reads the body of a page before its Responder decodes it.
+type pageResponder struct {
+	Responder
+	page *Page
+	err  error
+}
+
+// Respond reads the body of the page and calls the page's Responder with a
+// copy of it.
+// Implements Responder interface
+func (r *pageResponder) Respond(req *http.Request, resp *http.Response, err error) Responder {
+	if err == nil {
+		r.page.Body, r.err = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(r.page.Body))
+	}
+	r.page.Response = resp
+	return r.Responder.Respond(req, resp, err)
+}
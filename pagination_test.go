@@ -0,0 +1,164 @@
+package meteor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_Pages(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/link":
+			page, _ := strconv.Atoi(query.Get("page"))
+			if page < 2 {
+				w.Header().Set("Link", fmt.Sprintf(`</link?page=%d&apiKey=key>; rel="next", </link?page=3>; rel="last"`, page+1))
+			}
+			fmt.Fprintf(w, `{"items": [%d]}`, page)
+		case "/cursor":
+			cursor, _ := strconv.Atoi(query.Get("cursor"))
+			next := `null`
+			if cursor < 2 {
+				next = strconv.Itoa(cursor + 1)
+			}
+			fmt.Fprintf(w, `{"items": [%d], "meta": {"next": %s}}`, cursor, next)
+		case "/offset":
+			offset, _ := strconv.Atoi(query.Get("offset"))
+			limit, _ := strconv.Atoi(query.Get("limit"))
+			end := offset + limit
+			if end > len(items) {
+				end = len(items)
+			}
+			fmt.Fprintf(w, `{"items": %s}`, jsonInts(items[offset:end]))
+		case "/page":
+			page, _ := strconv.Atoi(query.Get("page"))
+			if page == 0 {
+				page = 1
+			}
+			if page > 2 {
+				w.Write([]byte(`[]`))
+				return
+			}
+			fmt.Fprintf(w, `[%d]`, page)
+		case "/endless":
+			w.Header().Set("Link", `</endless>; rel="next"`)
+			w.Write([]byte(`{"items": [0]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	type Items struct {
+		Items []int `json:"items"`
+	}
+	tests := []struct {
+		name      string
+		s         *Service
+		want      [][]int
+		wantQuery []string
+		wantErr   func(error) bool
+	}{
+		{"none", New().Base(server.URL).Path("link"), [][]int{{0}}, []string{""}, nil},
+		{"link", New().Base(server.URL).Path("link").Paginate(NewLinkPaginator()), [][]int{{0}, {1}, {2}}, []string{"", "page=1&apiKey=key", "page=2&apiKey=key"}, nil},
+		{"cursor", New().Base(server.URL).Path("cursor").Query("apiKey", "key").Paginate(NewCursorPaginator("meta.next", "cursor")), [][]int{{0}, {1}, {2}}, []string{"apiKey=key", "apiKey=key&cursor=1", "apiKey=key&cursor=2"}, nil},
+		{"offset", New().Base(server.URL).Path("offset").Query("limit", "2").Paginate(NewOffsetPaginator("offset", "limit", 2, "items")), [][]int{{1, 2}, {3, 4}, {5}}, []string{"limit=2", "limit=2&offset=2", "limit=2&offset=4"}, nil},
+		{"pageNumber", New().Base(server.URL).Path("page").Paginate(NewPageNumberPaginator("page", "")), [][]int{{1}, {2}, {}}, []string{"", "page=2", "page=3"}, nil},
+		{"maxPages", New().Base(server.URL).Path("endless").Paginate(NewLinkPaginator()).MaxPages(3), [][]int{{0}, {0}, {0}}, []string{"", "", ""}, func(err error) bool { return errors.Is(err, ErrMaxPages) }},
+		{"failure", New().Base(server.URL).Path("missing").Paginate(NewLinkPaginator()), [][]int{nil}, []string{""}, func(err error) bool { return IsAPIError(err, http.StatusNotFound) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]int
+			var query []string
+			var err error
+			svc := tt.s.ResponderFunc(func() Responder {
+				if tt.name == "pageNumber" {
+					return JSONSuccessResponder(&[]int{})
+				}
+				return JSONSuccessResponder(&Items{})
+			})
+			for page, pageErr := range svc.Pages(context.Background()) {
+				if pageErr != nil {
+					err = pageErr
+					if page == nil {
+						continue
+					}
+				}
+				assert.Equal(t, len(got)+1, page.Number)
+				query = append(query, page.Request.URL.RawQuery)
+				switch success := page.Responder.GetSuccess().(type) {
+				case *Items:
+					got = append(got, success.Items)
+				case *[]int:
+					got = append(got, *success)
+				}
+			}
+			if !assert.Equal(t, tt.want, got) {
+				t.Errorf("%v Service.Pages() = %v, want %v", tt.name, got, tt.want)
+			}
+			assert.Equal(t, tt.wantQuery, query)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else if !tt.wantErr(err) {
+				t.Errorf("%v Service.Pages() unexpected error = %v", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestService_Pages_crossOrigin(t *testing.T) {
+	var other *httptest.Server
+	var got []http.Header
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Clone())
+		if len(got) == 1 {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/items?page=2>; rel="next"`, other.URL))
+		}
+		w.Write([]byte(`{"items": [1]}`))
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	other = httptest.NewServer(handler)
+	defer other.Close()
+
+	svc := New().Base(server.URL).Path("items").
+		Set("Authorization", "Bearer token").
+		Set("Cookie", "session=secret").
+		Set("X-Request", "kept").
+		Paginate(NewLinkPaginator()).
+		ResponderFunc(func() Responder { return JSONSuccessResponder(&struct{}{}) })
+	pages := 0
+	for _, err := range svc.Pages(context.Background()) {
+		assert.NoError(t, err)
+		pages++
+	}
+	assert.Equal(t, 2, pages)
+	if assert.Len(t, got, 2) {
+		assert.Equal(t, "Bearer token", got[0].Get("Authorization"))
+		assert.Equal(t, "session=secret", got[0].Get("Cookie"))
+		assert.Empty(t, got[1].Get("Authorization"))
+		assert.Empty(t, got[1].Get("Cookie"))
+		assert.Equal(t, "kept", got[1].Get("X-Request"))
+	}
+}
+
+// jsonInts formats ints as a JSON array.
+func jsonInts(ints []int) string {
+	s := "["
+	for i, n := range ints {
+		if i > 0 {
+			s += ","
+		}
+		s += strconv.Itoa(n)
+	}
+	return s + "]"
+}
@@ -0,0 +1,243 @@
+package meteor
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	goquery "github.com/google/go-querystring/query"
+)
+
+// queryParam is a query parameter added with Query, or set with SetQuery.
+// encoded keeps the original encoding of a parameter parsed from a URL.
+type queryParam struct {
+	key     string
+	value   string
+	set     bool
+	encoded string
+}
+
+// queryParams are query parameters in the order they were added.
+type queryParams []queryParam
+
+// parseQueryParams parses an encoded query keeping the order of its parameters.
+func parseQueryParams(rawQuery string) (queryParams, error) {
+	var params queryParams
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err = url.QueryUnescape(value)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, queryParam{key: key, value: value, encoded: pair})
+	}
+	return params, nil
+}
+
+// add adds the values of key.
+func (q queryParams) add(key string, values ...string) queryParams {
+	for _, value := range values {
+		q = append(q, queryParam{key: key, value: value})
+	}
+	return q
+}
+
+// set replaces the values of key with value, at the position of its first value.
+func (q queryParams) set(key, value string) queryParams {
+	params := q[:0:0]
+	found := false
+	for _, p := range q {
+		if p.key != key {
+			params = append(params, p)
+		} else if !found {
+			params = append(params, queryParam{key: key, value: value})
+			found = true
+		}
+	}
+	if !found {
+		params = append(params, queryParam{key: key, value: value})
+	}
+	return params
+}
+
+// addSource adds the parameters of a query source: a queryParam, url.Values,
+// a map with string keys, or a url tagged struct. Keys of maps are added in
+// sorted order, fields of structs in the order they are declared.
+func (q queryParams) addSource(source interface{}) (queryParams, error) {
+	switch s := source.(type) {
+	case queryParam:
+		if s.set {
+			return q.set(s.key, s.value), nil
+		}
+		return q.add(s.key, s.value), nil
+	case url.Values:
+		return q.addValues(s), nil
+	case map[string][]string:
+		return q.addValues(s), nil
+	case map[string]string:
+		values := make(url.Values, len(s))
+		for key, value := range s {
+			values.Set(key, value)
+		}
+		return q.addValues(values), nil
+	}
+
+	v := reflect.ValueOf(source)
+	if v.Kind() == reflect.Map {
+		values := make(url.Values, v.Len())
+		for _, k := range v.MapKeys() {
+			key, value := fmt.Sprint(k.Interface()), v.MapIndex(k)
+			if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+				for i := 0; i < value.Len(); i++ {
+					values.Add(key, fmt.Sprint(value.Index(i).Interface()))
+				}
+				continue
+			}
+			values.Add(key, fmt.Sprint(value.Interface()))
+		}
+		return q.addValues(values), nil
+	}
+
+	values, err := goquery.Values(source)
+	if err != nil {
+		return nil, err
+	}
+	return q.addFields(values, queryFieldNames(reflect.TypeOf(source))), nil
+}
+
+// addFields adds the values of a url tagged struct in the order of its
+// field names. The keys of a nested struct follow its field, any other keys
+// are added last by sorted key.
+func (q queryParams) addFields(values url.Values, fields []string) queryParams {
+	added := make(map[string]bool, len(values))
+	for _, field := range fields {
+		if _, ok := values[field]; ok && !added[field] {
+			q = q.add(field, values[field]...)
+			added[field] = true
+		}
+		var nested []string
+		for key := range values {
+			if !added[key] && strings.HasPrefix(key, field+"[") {
+				nested = append(nested, key)
+			}
+		}
+		sort.Strings(nested)
+		for _, key := range nested {
+			q = q.add(key, values[key]...)
+			added[key] = true
+		}
+	}
+	rest := make(url.Values, len(values)-len(added))
+	for key, value := range values {
+		if !added[key] {
+			rest[key] = value
+		}
+	}
+	return q.addValues(rest)
+}
+
+// queryFieldNames returns the query keys of the fields of a url tagged struct
+// type in the order they are declared, named like go-querystring does.
+func queryFieldNames(t reflect.Type) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get("url")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				names = append(names, queryFieldNames(embedded)...)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// addValues adds the values by sorted key.
+func (q queryParams) addValues(values url.Values) queryParams {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		q = q.add(key, values[key]...)
+	}
+	return q
+}
+
+// encode encodes the parameters sorted by key, like url.Values, or in the
+// order they were added. Keys and values are escaped unless raw is set, in
+// which case parameters parsed from a URL keep their original encoding.
+func (q queryParams) encode(ordered, raw bool) string {
+	params := q
+	if !ordered {
+		params = append(queryParams{}, q...)
+		sort.SliceStable(params, func(i, j int) bool {
+			return params[i].key < params[j].key
+		})
+	}
+
+	escape := escapeQuery
+	if raw {
+		escape = func(s string) string { return s }
+	}
+	encoded := &strings.Builder{}
+	for i, p := range params {
+		if i > 0 {
+			encoded.WriteByte('&')
+		}
+		if raw && p.encoded != "" {
+			encoded.WriteString(p.encoded)
+			continue
+		}
+		encoded.WriteString(escape(p.key))
+		encoded.WriteByte('=')
+		encoded.WriteString(escape(p.value))
+	}
+	return encoded.String()
+}
+
+// escapeQuery escapes a query key or value like url.QueryEscape, but keeps
+// the characters that need no escaping within a query value (",:/@"), so
+// values such as geocodes remain readable.
+func escapeQuery(s string) string {
+	escaped := url.QueryEscape(s)
+	if !strings.Contains(escaped, "%") {
+		return escaped
+	}
+	return queryUnescaper.Replace(escaped)
+}
+
+// queryUnescaper unescapes the characters kept by escapeQuery.
+var queryUnescaper = strings.NewReplacer("%2C", ",", "%3A", ":", "%2F", "/", "%40", "@")
@@ -0,0 +1,52 @@
+package meteor
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestService_Query(t *testing.T) {
+	base := New().Base("https://example.com/").Path("search?geocode=34.06,-84.22")
+	tests := []struct {
+		name string
+		s    *Service
+		want string
+	}{
+		{"escaped", base.New().Query("query", "rain & snow+sleet=yes"), "geocode=34.06,-84.22&query=rain+%26+snow%2Bsleet%3Dyes"},
+		{"unescaped", base.New().Query("query", "a b").UnescapedQuery(true), "geocode=34.06,-84.22&query=a b"},
+		{"add", base.New().Query("b", "1").Query("a", "2").Query("b", "3"), "a=2&b=1&b=3&geocode=34.06,-84.22"},
+		{"ordered", base.New().Query("b", "1").Query("a", "2").Query("b", "3").OrderedQuery(true), "geocode=34.06,-84.22&b=1&a=2&b=3"},
+		{"set", base.New().Query("b", "1").Query("a", "2").Query("b", "3").SetQuery("b", "4").OrderedQuery(true), "geocode=34.06,-84.22&b=4&a=2"},
+		{"setBase", base.New().SetQuery("geocode", "1,2"), "geocode=1,2"},
+		{"values", base.New().QueryStruct(url.Values{"units": []string{"e"}, "language": []string{"en-US", "fr"}}), "geocode=34.06,-84.22&language=en-US&language=fr&units=e"},
+		{"map", base.New().QueryStruct(map[string]string{"units": "m", "format": "json"}), "format=json&geocode=34.06,-84.22&units=m"},
+		{"mapValues", base.New().QueryStruct(map[string]int{"days": 3}), "days=3&geocode=34.06,-84.22"},
+		{"struct", base.New().QueryStruct(&struct {
+			Units string `url:"units"`
+		}{"e"}).Query("apiKey", "x/y"), "apiKey=x/y&geocode=34.06,-84.22&units=e"},
+		{"structOrdered", base.New().QueryStruct(&struct {
+			Units    string `url:"units"`
+			Language string `url:"language"`
+			Days     int
+		}{"e", "en-US", 3}).OrderedQuery(true), "geocode=34.06,-84.22&units=e&language=en-US&Days=3"},
+		{"unescapedBase", New().Base("https://example.com/").Path("search?q=a%26b&r=c+d").Query("x", "e f").UnescapedQuery(true), "q=a%26b&r=c+d&x=e f"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := tt.s.Request()
+			if err != nil {
+				t.Fatalf("%v Service.Request() error = %v", tt.name, err)
+			}
+			if got := req.URL.RawQuery; got != tt.want {
+				t.Errorf("%v Service.Request() query = %v, want %v", tt.name, got, tt.want)
+			}
+			if tt.s.queryRaw {
+				return
+			}
+			// the encoded query decodes to the values that were given
+			if _, err := url.ParseQuery(req.URL.RawQuery); err != nil {
+				t.Errorf("%v url.ParseQuery() error = %v", tt.name, err)
+			}
+		})
+	}
+}
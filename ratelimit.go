@@ -0,0 +1,174 @@
+package meteor
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket rate limiter with one bucket per key, such as
+// a credential name or a host. Requests wait for a token, honoring their
+// context. The buckets adapt to the X-RateLimit-Remaining, RateLimit-Remaining,
+// X-RateLimit-Reset and RateLimit-Reset response headers, and to the
+// Retry-After header of 429 responses. A RateLimiter is safe for concurrent use.
+type RateLimiter struct {
+	// Rate is the number of requests per second allowed for each key.
+	// A value of 0 or less only limits requests from response headers.
+	Rate float64
+
+	// Burst is the number of requests that may be sent at once.
+	Burst int
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// bucket holds the tokens of a key.
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// blocked until the quota of the key is reset
+	until time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing rate requests per second
+// for each key, in bursts of at most burst requests.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		Rate:  rate,
+		Burst: burst,
+	}
+}
+
+// Wait waits until a request of key may be sent, or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context, key string) error {
+	for {
+		wait := l.reserve(key)
+		if wait <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Middleware returns the RateLimiter as a Middleware waiting on the bucket
+// of the request's host. The bodies of requests that stop waiting are closed.
+func (l *RateLimiter) Middleware() Middleware {
+	return l.middleware("")
+}
+
+// middleware waits on the bucket of key, or of the request's host if key
+// is empty.
+func (l *RateLimiter) middleware(key string) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			k := key
+			if k == "" {
+				k = req.URL.Host
+			}
+			if err := l.Wait(req.Context(), k); err != nil {
+				closeRequestBody(req)
+				return nil, err
+			}
+			resp, err := next.Do(req)
+			if resp != nil {
+				l.adapt(k, resp)
+			}
+			return resp, err
+		})
+	}
+}
+
+// bucket gets the bucket of key. l.mu must be held.
+func (l *RateLimiter) bucket(key string, now time.Time) *bucket {
+	if l.buckets == nil {
+		l.buckets = make(map[string]*bucket)
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.Burst), last: now}
+		l.buckets[key] = b
+	}
+	if l.Rate > 0 {
+		b.tokens = math.Min(float64(l.Burst), b.tokens+now.Sub(b.last).Seconds()*l.Rate)
+	}
+	b.last = now
+	return b
+}
+
+// reserve takes a token of key, or returns how long to wait for one.
+func (l *RateLimiter) reserve(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	b := l.bucket(key, now)
+	if now.Before(b.until) {
+		return b.until.Sub(now)
+	}
+	if l.Rate <= 0 {
+		return 0
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / l.Rate * float64(time.Second))
+}
+
+// adapt updates the bucket of key from the rate limit headers of resp.
+func (l *RateLimiter) adapt(key string, resp *http.Response) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	b := l.bucket(key, now)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if after, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			b.until = now.Add(after)
+			return
+		}
+	}
+	remaining, ok := rateLimitHeader(resp.Header, "Remaining")
+	if !ok {
+		return
+	}
+	if remaining < b.tokens || l.Rate <= 0 {
+		b.tokens = remaining
+	}
+	if remaining < 1 {
+		if reset, ok := rateLimitHeader(resp.Header, "Reset"); ok {
+			b.until = now.Add(rateLimitReset(reset, now))
+		}
+	}
+}
+
+// rateLimitHeader parses the X-RateLimit-<name> or RateLimit-<name> header.
+func rateLimitHeader(header http.Header, name string) (float64, bool) {
+	value := header.Get("X-RateLimit-" + name)
+	if value == "" {
+		value = header.Get("RateLimit-" + name)
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	return n, err == nil && n >= 0
+}
+
+// rateLimitReset returns the wait until a reset given either in seconds or,
+// for large values, as a Unix time.
+func rateLimitReset(reset float64, now time.Time) time.Duration {
+	if reset > 1e9 {
+		return time.Unix(int64(reset), 0).Sub(now)
+	}
+	return time.Duration(reset * float64(time.Second))
+}
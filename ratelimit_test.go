@@ -0,0 +1,140 @@
+package meteor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Wait(t *testing.T) {
+	tests := []struct {
+		name     string
+		rate     float64
+		burst    int
+		requests int
+		minWait  time.Duration
+	}{
+		{"burst", 10, 3, 3, 0},
+		{"limited", 50, 1, 4, 55 * time.Millisecond},
+		{"unlimited", 0, 1, 10, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(tt.rate, tt.burst)
+			start := time.Now()
+			for i := 0; i < tt.requests; i++ {
+				if err := limiter.Wait(context.Background(), "key"); err != nil {
+					t.Fatalf("%v RateLimiter.Wait() error = %v", tt.name, err)
+				}
+			}
+			elapsed := time.Since(start)
+			if elapsed < tt.minWait || (tt.minWait == 0 && elapsed > 20*time.Millisecond) {
+				t.Errorf("%v RateLimiter.Wait() took %v, want at least %v", tt.name, elapsed, tt.minWait)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_Wait_context(t *testing.T) {
+	limiter := NewRateLimiter(0.1, 1)
+	limiter.Wait(context.Background(), "key")
+	// another key has its own bucket
+	if err := limiter.Wait(context.Background(), "other"); err != nil {
+		t.Errorf("RateLimiter.Wait() other key error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx, "key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("RateLimiter.Wait() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestRateLimiter_Middleware_context(t *testing.T) {
+	limiter := NewRateLimiter(0.1, 1)
+	doer := Chain(DoerFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}, nil
+	}), limiter.Middleware())
+
+	req, _ := http.NewRequest("GET", "https://example.com", nil)
+	if _, err := doer.Do(req); err != nil {
+		t.Fatalf("Doer.Do() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	body := &closeRecorder{Reader: strings.NewReader("body")}
+	req, _ = http.NewRequestWithContext(ctx, "POST", "https://example.com", body)
+	if _, err := doer.Do(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("Doer.Do() error = %v, want %v", err, context.Canceled)
+	}
+	if !body.closed {
+		t.Errorf("Doer.Do() did not close the request body")
+	}
+}
+
+func TestService_RateLimit_headers(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  http.Header
+		status  int
+		minWait time.Duration
+	}{
+		{"remaining", http.Header{"X-Ratelimit-Remaining": []string{"5"}}, http.StatusOK, 0},
+		{"exhausted", http.Header{"X-Ratelimit-Remaining": []string{"0"}, "X-Ratelimit-Reset": []string{"1"}}, http.StatusOK, 900 * time.Millisecond},
+		{"draft", http.Header{"Ratelimit-Remaining": []string{"0"}, "Ratelimit-Reset": []string{"1"}}, http.StatusOK, 900 * time.Millisecond},
+		{"retryAfter", http.Header{"Retry-After": []string{"1"}}, http.StatusTooManyRequests, 900 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.header {
+					w.Header()[k] = v
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			limiter := NewRateLimiter(0, 1)
+			svc := New().Base(server.URL).RateLimit(limiter, "apiKey")
+			if _, err := svc.New().Do(); err != nil {
+				t.Fatalf("%v Service.Do() error = %v", tt.name, err)
+			}
+
+			start := time.Now()
+			if _, err := svc.New().Do(); err != nil {
+				t.Fatalf("%v Service.Do() error = %v", tt.name, err)
+			}
+			elapsed := time.Since(start)
+			if elapsed < tt.minWait || (tt.minWait == 0 && elapsed > 500*time.Millisecond) {
+				t.Errorf("%v Service.Do() waited %v, want %v", tt.name, elapsed, tt.minWait)
+			}
+		})
+	}
+}
+
+func TestService_RateLimit_async(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	svc := New().Base(server.URL).RateLimit(NewRateLimiter(100, 1))
+	var reqs []AsyncDoer
+	for i := 0; i < 5; i++ {
+		reqs = append(reqs, svc.New().Path(strconv.Itoa(i)).AsyncRequest(nil))
+	}
+
+	start := time.Now()
+	for _, r := range svc.DoAsync(reqs) {
+		if r.GetError() != nil {
+			t.Errorf("Service.DoAsync() error = %v", r.GetError())
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("Service.DoAsync() took %v, want the requests to wait on the limiter", elapsed)
+	}
+}
@@ -46,6 +46,23 @@ func (r *binaryResponder) IsOK(statusCode int, resp *http.Response) bool {
 	return isOk(statusCode, resp)
 }
 
+// clone creates a fresh binaryResponder for a single call.
+func (r *binaryResponder) clone() Responder {
+	return (*binaryResponder)((*responder)(r).copyTo(&responder{}))
+}
+
+// fill records the results of the call made with a copy of the responder.
+func (r *binaryResponder) fill(call Responder) {
+	if c, ok := call.(*binaryResponder); ok {
+		(*responder)(r).fillFrom((*responder)(c))
+	}
+}
+
+// setAPIErrors sets the APIErrors and Redactor settings of the Service.
+func (r *binaryResponder) setAPIErrors(opts apiErrorOptions) {
+	(*responder)(r).setAPIErrors(opts)
+}
+
 // Respond creates the proper response object.
 func (r *binaryResponder) Respond(req *http.Request, resp *http.Response, err error) Responder {
 	r.mu.Lock()
@@ -59,6 +76,7 @@ func (r *binaryResponder) Respond(req *http.Request, resp *http.Response, err er
 }
 
 // DoResponse does the actual response falling back on JSONResponse for errors.
+// Responses that are not OK return an *APIError.
 func (r *binaryResponder) DoResponse() (*http.Response, error) {
 	r.mu.Lock()
 	defer func(br *binaryResponder) {
@@ -67,6 +85,10 @@ func (r *binaryResponder) DoResponse() (*http.Response, error) {
 	}(r)
 
 	ok := r.isOk(r.Response.StatusCode, r.Response)
+	var raw []byte
+	if !ok {
+		raw = captureErrorBody(r.Response)
+	}
 	if ok {
 		r.Success, r.Error = ioutil.ReadAll(r.Response.Body)
 	} else if r.Failure != nil {
@@ -82,6 +104,9 @@ func (r *binaryResponder) DoResponse() (*http.Response, error) {
 			}
 		}
 	}
+	if !ok && r.Error == nil {
+		r.Error = failureError(r.apiErrors, r.Request, r.Response, raw, r.Failure)
+	}
 
 	return r.Response, r.Error
 }
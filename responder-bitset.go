@@ -48,6 +48,23 @@ func (r *bitsetResponder) IsOK(statusCode int, resp *http.Response) bool {
 	return isOk(statusCode, resp)
 }
 
+// clone creates a fresh bitsetResponder for a single call.
+func (r *bitsetResponder) clone() Responder {
+	return (*bitsetResponder)((*responder)(r).copyTo(&responder{}))
+}
+
+// fill records the results of the call made with a copy of the responder.
+func (r *bitsetResponder) fill(call Responder) {
+	if c, ok := call.(*bitsetResponder); ok {
+		(*responder)(r).fillFrom((*responder)(c))
+	}
+}
+
+// setAPIErrors sets the APIErrors and Redactor settings of the Service.
+func (r *bitsetResponder) setAPIErrors(opts apiErrorOptions) {
+	(*responder)(r).setAPIErrors(opts)
+}
+
 // Respond creates the proper response object.
 func (r *bitsetResponder) Respond(req *http.Request, resp *http.Response, err error) Responder {
 	r.mu.Lock()
@@ -61,6 +78,7 @@ func (r *bitsetResponder) Respond(req *http.Request, resp *http.Response, err er
 }
 
 // DoResponse does the actual response falling back on JSONResponse for errors.
+// Responses that are not OK return an *APIError.
 func (r *bitsetResponder) DoResponse() (*http.Response, error) {
 	r.mu.Lock()
 	defer func(br *bitsetResponder) {
@@ -69,6 +87,10 @@ func (r *bitsetResponder) DoResponse() (*http.Response, error) {
 	}(r)
 
 	ok := r.isOk(r.Response.StatusCode, r.Response)
+	var raw []byte
+	if !ok {
+		raw = captureErrorBody(r.Response)
+	}
 	if ok {
 		var contents []byte
 		contents, r.Error = ioutil.ReadAll(r.Response.Body)
@@ -76,6 +98,9 @@ func (r *bitsetResponder) DoResponse() (*http.Response, error) {
 	} else if r.Failure != nil {
 		r.Error = decodeResponseJSON(r.IsOK, r.Response, nil, r.Failure)
 	}
+	if !ok && r.Error == nil {
+		r.Error = failureError(r.apiErrors, r.Request, r.Response, raw, r.Failure)
+	}
 
 	return r.Response, r.Error
 }
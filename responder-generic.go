@@ -13,3 +13,17 @@ func GenericResponder() *genericResponder {
 type genericResponder struct {
 	responder
 }
+
+// clone creates a fresh genericResponder for a single call.
+func (r *genericResponder) clone() Responder {
+	g := &genericResponder{}
+	r.copyTo(&g.responder)
+	return g
+}
+
+// fill records the results of the call made with a copy of the responder.
+func (r *genericResponder) fill(call Responder) {
+	if c, ok := call.(*genericResponder); ok {
+		r.fillFrom(&c.responder)
+	}
+}
@@ -44,6 +44,23 @@ func (r *jsonResponder) IsOK(statusCode int, resp *http.Response) bool {
 	return isOk(statusCode, resp)
 }
 
+// clone creates a fresh jsonResponder for a single call.
+func (r *jsonResponder) clone() Responder {
+	return (*jsonResponder)((*responder)(r).copyTo(&responder{}))
+}
+
+// fill records the results of the call made with a copy of the responder.
+func (r *jsonResponder) fill(call Responder) {
+	if c, ok := call.(*jsonResponder); ok {
+		(*responder)(r).fillFrom((*responder)(c))
+	}
+}
+
+// setAPIErrors sets the APIErrors and Redactor settings of the Service.
+func (r *jsonResponder) setAPIErrors(opts apiErrorOptions) {
+	(*responder)(r).setAPIErrors(opts)
+}
+
 // Respond creates the proper response object.
 func (r *jsonResponder) Respond(req *http.Request, resp *http.Response, err error) Responder {
 	r.mu.Lock()
@@ -57,15 +74,22 @@ func (r *jsonResponder) Respond(req *http.Request, resp *http.Response, err erro
 }
 
 // DoResponse does the actual response decoding from json.
+// Responses that are not OK return an *APIError.
 func (r *jsonResponder) DoResponse() (*http.Response, error) {
-	if r.GetSuccess() != nil || r.GetFailure() != nil {
-		r.mu.Lock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ok := r.IsOK(r.Response.StatusCode, r.Response)
+	var raw []byte
+	if !ok {
+		raw = captureErrorBody(r.Response)
+	}
+	if r.Success != nil || r.Failure != nil {
 		r.Error = decodeResponseJSON(r.IsOK, r.Response, r.Success, r.Failure)
-		r.mu.Unlock()
 	}
-
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+	if !ok && r.Error == nil {
+		r.Error = failureError(r.apiErrors, r.Request, r.Response, raw, r.Failure)
+	}
 	return r.Response, r.Error
 }
 
@@ -263,7 +263,7 @@ func Test_decodeResponseJSON(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := decodeResponseJSON(tt.args.resp, tt.args.successV, tt.args.failureV); (err != nil) != tt.wantErr {
+			if err := decodeResponseJSON(isOk, tt.args.resp, tt.args.successV, tt.args.failureV); (err != nil) != tt.wantErr {
 				t.Errorf("decodeResponseJSON() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -0,0 +1,285 @@
+package meteor
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrNoDecoder is returned, wrapped with the media type, when no Decoder of
+// a DecoderRegistry matches the Content-Type of a response.
+var ErrNoDecoder = errors.New("meteor: no decoder for media type")
+
+// Decoder decodes the body of a response into the value pointed to by v.
+type Decoder func(resp *http.Response, v interface{}) error
+
+// decoderEntry is a Decoder registered for a media range.
+type decoderEntry struct {
+	mediaRange string
+	decoder    Decoder
+}
+
+// DecoderRegistry maps media ranges to the Decoders of their responses.
+// Media ranges are media types, such as "application/json", or patterns:
+// "image/*" matches every image type, "application/*+json" every JSON based
+// type, and "*/*" any type. The most specific range matching a Content-Type
+// wins. A DecoderRegistry is safe for concurrent use.
+type DecoderRegistry struct {
+	mu      sync.RWMutex
+	entries []decoderEntry
+}
+
+// DefaultDecoders is the DecoderRegistry used by NegotiatingResponder when
+// none is given. It decodes JSON, XML and protobuf (proto.Message values),
+// text into *string values and images and other binary types into *[]byte
+// values. Text and binary bodies are also copied into io.Writers and
+// decoded by encoding.TextUnmarshalers and encoding.BinaryUnmarshalers,
+// such as *bitset.BitSet.
+var DefaultDecoders = NewDecoderRegistry().
+	Register(jsonContentType, decodeResponseBodyJSON).
+	Register("application/*+json", decodeResponseBodyJSON).
+	Register(xmlContentType, decodeResponseBodyXML).
+	Register("text/xml", decodeResponseBodyXML).
+	Register("application/*+xml", decodeResponseBodyXML).
+	Register(protobufContentType, decodeResponseBodyProtobuf).
+	Register("application/protobuf", decodeResponseBodyProtobuf).
+	Register("text/*", decodeResponseBodyText).
+	Register("image/*", decodeResponseBodyBinary).
+	Register(octetStreamContentType, decodeResponseBodyBinary)
+
+// NewDecoderRegistry returns an empty DecoderRegistry.
+func NewDecoderRegistry() *DecoderRegistry {
+	return &DecoderRegistry{}
+}
+
+// Register registers the decoder of the media range, replacing any decoder
+// registered for it. The Accept header lists media ranges in the order they
+// were first registered.
+func (r *DecoderRegistry) Register(mediaRange string, decoder Decoder) *DecoderRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mediaRange = strings.ToLower(mediaRange)
+	for i, entry := range r.entries {
+		if entry.mediaRange == mediaRange {
+			r.entries[i].decoder = decoder
+			return r
+		}
+	}
+	r.entries = append(r.entries, decoderEntry{mediaRange: mediaRange, decoder: decoder})
+	return r
+}
+
+// Clone returns a copy of the registry, e.g. to register formats for some
+// Services only.
+func (r *DecoderRegistry) Clone() *DecoderRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return &DecoderRegistry{entries: append([]decoderEntry(nil), r.entries...)}
+}
+
+// Accept gets the value of an Accept header for the registered media
+// ranges. Patterns with a structured syntax suffix, which the Accept header
+// does not support, are left out.
+func (r *DecoderRegistry) Accept() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ranges := make([]string, 0, len(r.entries))
+	for _, entry := range r.entries {
+		if !strings.Contains(entry.mediaRange, "*+") {
+			ranges = append(ranges, entry.mediaRange)
+		}
+	}
+	return strings.Join(ranges, ", ")
+}
+
+// Lookup gets the Decoder of the most specific media range matching the
+// content type. A missing content type is application/octet-stream.
+func (r *DecoderRegistry) Lookup(contentType string) (Decoder, bool) {
+	mediaType := octetStreamContentType
+	if contentType != "" {
+		parsed, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, false
+		}
+		mediaType = parsed
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var decoder Decoder
+	best := 0
+	for _, entry := range r.entries {
+		if score := matchMediaRange(entry.mediaRange, mediaType); score > best {
+			decoder, best = entry.decoder, score
+		}
+	}
+	return decoder, decoder != nil
+}
+
+// matchMediaRange scores how specifically the media range matches the media
+// type, 0 meaning it does not.
+func matchMediaRange(mediaRange, mediaType string) int {
+	rangeType, rangeSubtype, _ := strings.Cut(mediaRange, "/")
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	if rangeType != "*" && rangeType != typ {
+		return 0
+	}
+
+	score := 0
+	switch {
+	case rangeSubtype == subtype:
+		score = 6
+	case strings.HasPrefix(rangeSubtype, "*+") && strings.HasSuffix(subtype, rangeSubtype[1:]):
+		score = 4
+	case rangeSubtype == "*":
+		score = 2
+	default:
+		return 0
+	}
+	if rangeType != "*" {
+		score++
+	}
+	return score
+}
+
+/** Negotiating Responder */
+// NegotiatingResponder creates a response decoding Success and Failure with
+// the Decoder of the response Content-Type, found in the registry, or in
+// DefaultDecoders if registry is nil.
+func NegotiatingResponder(registry *DecoderRegistry, success, failure interface{}, isOKfn ...func(int, *http.Response) bool) *negotiatingResponder {
+	if registry == nil {
+		registry = DefaultDecoders
+	}
+	nr := &negotiatingResponder{
+		responder: responder{
+			Failure: failure,
+			Success: success,
+			isOk:    isOk,
+		},
+		registry: registry,
+	}
+
+	if len(isOKfn) > 0 {
+		nr.isOk = isOKfn[0]
+	}
+
+	return nr
+}
+
+// negotiatingResponder
+type negotiatingResponder struct {
+	responder
+	registry *DecoderRegistry
+}
+
+// clone creates a fresh negotiatingResponder for a single call.
+func (r *negotiatingResponder) clone() Responder {
+	nr := &negotiatingResponder{registry: r.registry}
+	r.copyTo(&nr.responder)
+	return nr
+}
+
+// fill records the results of the call made with a copy of the responder.
+func (r *negotiatingResponder) fill(call Responder) {
+	if c, ok := call.(*negotiatingResponder); ok {
+		r.fillFrom(&c.responder)
+	}
+}
+
+// Respond creates the proper response object.
+func (r *negotiatingResponder) Respond(req *http.Request, resp *http.Response, err error) Responder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Request = req
+	r.Response = resp
+	r.Error = err
+
+	return r
+}
+
+// DoResponse decodes the response with the Decoder of its Content-Type.
+// Success responses with no Decoder return an ErrNoDecoder. Failures are
+// decoded when a Decoder matches, and return an *APIError.
+func (r *negotiatingResponder) DoResponse() (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ok := r.IsOK(r.Response.StatusCode, r.Response)
+	ct := r.Response.Header.Get(contentType)
+	decoder, found := r.registry.Lookup(ct)
+	if ok {
+		r.Error = nil
+		if r.Success != nil {
+			if !found {
+				r.Error = fmt.Errorf("%w: %q", ErrNoDecoder, ct)
+			} else {
+				r.Error = decoder(r.Response, r.Success)
+			}
+		}
+		return r.Response, r.Error
+	}
+
+	raw := captureErrorBody(r.Response)
+	if r.Failure != nil && found {
+		decoder(r.Response, r.Failure)
+	}
+	r.Error = failureError(r.apiErrors, r.Request, r.Response, raw, r.Failure)
+	return r.Response, r.Error
+}
+
+// decodeResponseBodyBinary reads a Response Body into the *[]byte v, copies
+// it into an io.Writer, or decodes it with an encoding.BinaryUnmarshaler.
+// Caller must provide a non-nil v and close the resp.Body.
+func decodeResponseBodyBinary(resp *http.Response, v interface{}) error {
+	if w, ok := v.(io.Writer); ok {
+		_, err := io.Copy(w, resp.Body)
+		return err
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case *[]byte:
+		*v = data
+		return nil
+	case encoding.BinaryUnmarshaler:
+		return v.UnmarshalBinary(data)
+	}
+	return fmt.Errorf("meteor: cannot decode binary into %T", v)
+}
+
+// decodeResponseBodyText reads a Response Body into the *string v, copies it
+// into an io.Writer, or decodes it with an encoding.TextUnmarshaler.
+// Caller must provide a non-nil v and close the resp.Body.
+func decodeResponseBodyText(resp *http.Response, v interface{}) error {
+	if w, ok := v.(io.Writer); ok {
+		_, err := io.Copy(w, resp.Body)
+		return err
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case *string:
+		*v = string(data)
+		return nil
+	case *[]byte:
+		*v = data
+		return nil
+	case encoding.TextUnmarshaler:
+		return v.UnmarshalText(data)
+	}
+	return fmt.Errorf("meteor: cannot decode text into %T", v)
+}
@@ -0,0 +1,200 @@
+package meteor
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/willf/bitset"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestDecoderRegistry_Lookup(t *testing.T) {
+	var got string
+	decoder := func(name string) Decoder {
+		return func(resp *http.Response, v interface{}) error {
+			got = name
+			return nil
+		}
+	}
+	registry := NewDecoderRegistry().
+		Register("*/*", decoder("any")).
+		Register("image/*", decoder("image")).
+		Register("image/PNG", decoder("png")).
+		Register("application/*+json", decoder("json+"))
+
+	tests := []struct {
+		name        string
+		contentType string
+		want        string
+		wantFound   bool
+	}{
+		{"exact", "image/png", "png", true},
+		{"params", "Image/PNG; q=1", "png", true},
+		{"typeWildcard", "image/gif", "image", true},
+		{"suffix", "application/problem+json; charset=utf-8", "json+", true},
+		{"anyWildcard", "text/plain", "any", true},
+		{"missing", "", "any", true},
+		{"invalid", "image/", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = ""
+			d, found := registry.Lookup(tt.contentType)
+			if found {
+				d(nil, nil)
+			}
+			if found != tt.wantFound || got != tt.want {
+				t.Errorf("%v DecoderRegistry.Lookup(%q) = %v, %v, want %v, %v", tt.name, tt.contentType, got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+
+	if _, found := NewDecoderRegistry().Lookup(jsonContentType); found {
+		t.Errorf("empty DecoderRegistry.Lookup() found a decoder")
+	}
+}
+
+func TestDecoderRegistry_Accept(t *testing.T) {
+	registry := NewDecoderRegistry().
+		Register(jsonContentType, decodeResponseBodyJSON).
+		Register("application/*+json", decodeResponseBodyJSON).
+		Register("image/*", decodeResponseBodyBinary).
+		Register(jsonContentType, decodeResponseBodyText)
+	assert.Equal(t, "application/json, image/*", registry.Accept())
+
+	clone := registry.Clone().Register(textContentType, decodeResponseBodyText)
+	assert.Equal(t, "application/json, image/*, text/plain", clone.Accept())
+	assert.Equal(t, "application/json, image/*", registry.Accept())
+}
+
+func TestService_NegotiatingResponder(t *testing.T) {
+	forecast, _ := proto.Marshal(wrapperspb.String("sunny"))
+	bits, _ := bitset.New(8).Set(3).MarshalBinary()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set(contentType, "application/json; charset=utf-8")
+			w.Write([]byte(`{"text": "sunny"}`))
+		case "/png":
+			w.Header().Set(contentType, "image/png")
+			w.Write([]byte("png"))
+		case "/protobuf":
+			w.Header().Set(contentType, protobufContentType)
+			w.Write(forecast)
+		case "/bitset":
+			w.Header().Set(contentType, octetStreamContentType)
+			w.Write(bits)
+		case "/problem":
+			w.Header().Set(contentType, "application/problem+json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message": "invalid station"}`))
+		case "/html":
+			w.Header().Set(contentType, "text/html")
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte(`<html><body>Bad Gateway</html>`))
+		case "/video":
+			w.Header().Set(contentType, "video/mp4")
+			w.Write([]byte("mp4"))
+		}
+	}))
+	defer server.Close()
+
+	type message struct {
+		Text    string `json:"text"`
+		Message string `json:"message"`
+	}
+	tests := []struct {
+		name        string
+		path        string
+		success     interface{}
+		failure     interface{}
+		wantSuccess interface{}
+		wantFailure interface{}
+		wantErr     error
+		wantAPIErr  bool
+	}{
+		{"json", "json", &message{}, &message{}, &message{Text: "sunny"}, &message{}, nil, false},
+		{"image", "png", &[]byte{}, nil, &[]byte{'p', 'n', 'g'}, nil, nil, false},
+		{"writer", "png", &bytes.Buffer{}, nil, bytes.NewBufferString("png"), nil, nil, false},
+		{"protobuf", "protobuf", &wrapperspb.StringValue{}, nil, wrapperspb.String("sunny"), nil, nil, false},
+		{"bitset", "bitset", &bitset.BitSet{}, nil, bitset.New(8).Set(3), nil, nil, false},
+		{"failure", "problem", &message{}, &message{}, &message{}, &message{Message: "invalid station"}, nil, true},
+		{"failureNoDecoder", "html", &message{}, &message{}, &message{}, &message{}, nil, true},
+		{"noDecoder", "video", &[]byte{}, nil, &[]byte{}, nil, ErrNoDecoder, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := New().Base(server.URL).Get(tt.path).NegotiatingResponder(nil, tt.success, tt.failure)
+			_, err := svc.Do()
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("%v Service.Do() error = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (err != nil) != tt.wantAPIErr || IsAPIError(err) != tt.wantAPIErr {
+				t.Errorf("%v Service.Do() error = %v, wantAPIErr %v", tt.name, err, tt.wantAPIErr)
+			}
+			if msg, ok := tt.wantSuccess.(proto.Message); ok {
+				assert.True(t, proto.Equal(msg, svc.GetSuccess().(proto.Message)))
+			} else {
+				assert.Equal(t, tt.wantSuccess, svc.GetSuccess())
+			}
+			assert.Equal(t, tt.wantFailure, svc.GetFailure())
+		})
+	}
+}
+
+func TestService_NegotiatingResponder_Accept(t *testing.T) {
+	var accept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		w.Header().Set(contentType, "application/vnd.meteor.csv")
+		w.Write([]byte("sunny,21"))
+	}))
+	defer server.Close()
+
+	var rows [][]byte
+	registry := DefaultDecoders.Clone().Register("application/vnd.meteor.csv", func(resp *http.Response, v interface{}) error {
+		var b []byte
+		if err := decodeResponseBodyBinary(resp, &b); err != nil {
+			return err
+		}
+		*v.(*[][]byte) = bytes.Split(b, []byte(","))
+		return nil
+	})
+	_, err := New().Base(server.URL).NegotiatingResponder(registry, &rows, nil).Do()
+	if err != nil {
+		t.Fatalf("Service.Do() error = %v", err)
+	}
+	assert.Equal(t, registry.Accept(), accept)
+	assert.Contains(t, accept, "image/*")
+	assert.Contains(t, accept, "application/vnd.meteor.csv")
+	assert.Equal(t, [][]byte{[]byte("sunny"), []byte("21")}, rows)
+
+	_, found := DefaultDecoders.Lookup("application/vnd.meteor.csv")
+	assert.False(t, found)
+
+	tests := []struct {
+		name string
+		s    *Service
+		want string
+	}{
+		{"responder", New().Base(server.URL).Responder(NegotiatingResponder(registry, &rows, nil)), registry.Accept()},
+		{"responderFunc", New().Base(server.URL).ResponderFunc(func() Responder { return NegotiatingResponder(registry, &[][]byte{}, nil) }), registry.Accept()},
+		{"responderFuncReplaced", New().Base(server.URL).NegotiatingResponder(registry, &rows, nil).ResponderFunc(func() Responder { return BinaryResponder(nil) }), ""},
+		{"header", New().Base(server.URL).Set("Accept", "text/csv").NegotiatingResponder(registry, &rows, nil), "text/csv"},
+		{"replaced", New().Base(server.URL).NegotiatingResponder(registry, &rows, nil).BinaryResponder(nil), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accept = ""
+			if _, err := tt.s.Do(); err != nil {
+				t.Fatalf("%v Service.Do() error = %v", tt.name, err)
+			}
+			assert.Equal(t, tt.want, accept)
+		})
+	}
+}
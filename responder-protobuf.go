@@ -1,9 +1,12 @@
 package meteor
 
 import (
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+
+	"google.golang.org/protobuf/proto"
 )
 
 /** Protobuf Responder */
@@ -42,6 +45,23 @@ func (r *protobufResponder) IsOK(statusCode int, resp *http.Response) bool {
 	return isOk(statusCode, resp)
 }
 
+// clone creates a fresh protobufResponder for a single call.
+func (r *protobufResponder) clone() Responder {
+	return (*protobufResponder)((*responder)(r).copyTo(&responder{}))
+}
+
+// fill records the results of the call made with a copy of the responder.
+func (r *protobufResponder) fill(call Responder) {
+	if c, ok := call.(*protobufResponder); ok {
+		(*responder)(r).fillFrom((*responder)(c))
+	}
+}
+
+// setAPIErrors sets the APIErrors and Redactor settings of the Service.
+func (r *protobufResponder) setAPIErrors(opts apiErrorOptions) {
+	(*responder)(r).setAPIErrors(opts)
+}
+
 // Respond creates the proper response object.
 func (r *protobufResponder) Respond(req *http.Request, resp *http.Response, err error) Responder {
 	r.mu.Lock()
@@ -55,15 +75,22 @@ func (r *protobufResponder) Respond(req *http.Request, resp *http.Response, err
 }
 
 // DoResponse does the actual response decoding from protobuf.
+// Responses that are not OK return an *APIError.
 func (r *protobufResponder) DoResponse() (*http.Response, error) {
-	if r.GetSuccess() != nil || r.GetFailure() != nil {
-		r.mu.Lock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ok := r.IsOK(r.Response.StatusCode, r.Response)
+	var raw []byte
+	if !ok {
+		raw = captureErrorBody(r.Response)
+	}
+	if r.Success != nil || r.Failure != nil {
 		r.Error = decodeResponseProtobuf(r.IsOK, r.Response, r.Success, r.Failure)
-		r.mu.Unlock()
 	}
-
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+	if !ok && r.Error == nil {
+		r.Error = failureError(r.apiErrors, r.Request, r.Response, raw, r.Failure)
+	}
 	return r.Response, r.Error
 }
 
@@ -93,41 +120,39 @@ func (r *protobufResponder) GetError() error {
 	return r.Error
 }
 
-// decodeResponse decodes response Body into the value pointed to by successV
-// if the response is a success (2XX) or into the value pointed to by failureV
-// otherwise. If the successV or failureV argument to decode into is nil,
-// decodig is skipped.
+// decodeResponseProtobuf decodes response Body into the message pointed to
+// by successV if the response is a success (2XX) or into the message pointed
+// to by failureV otherwise. If the successV or failureV argument to decode
+// into is nil, decoding is skipped. Failures that are not protobuf, such as an
+// HTML error page, are not an error, so the *APIError with the raw body is
+// returned.
 // Caller is responsible for closing the resp.Body.
-func decodeResponseProtobuf(okFn func(int, *http.Response) bool, resp *http.Response, successV, failureV interface{}) (err error) {
-	if okFn(resp.StatusCode, resp) && successV != nil {
-		err = decodeResponseBodyProtobuf(resp, successV)
-		// For some reason this is considered an ineffectual assignment
-		if err != nil {
-			successV, err = ioutil.ReadAll(resp.Body)
+func decodeResponseProtobuf(okFn func(int, *http.Response) bool, resp *http.Response, successV, failureV interface{}) error {
+	if okFn(resp.StatusCode, resp) {
+		if successV != nil {
+			return decodeResponseBodyProtobuf(resp, successV)
 		}
-		return err
 	} else if failureV != nil {
-		err = decodeResponseBodyProtobuf(resp, failureV)
-		// For some reason this is considered an ineffectual assignment
-		if err != nil {
-			failureV, err = ioutil.ReadAll(resp.Body)
-		}
-		return err
+		decodeResponseBodyProtobuf(resp, failureV)
 	}
 	return nil
 }
 
-// decodeResponseBodyProtobuf Protobuf decodes a Response Body into the value pointed
-// to by v.
+// decodeResponseBodyProtobuf Protobuf decodes a Response Body into the
+// proto.Message v, or copies it if v is an io.Writer.
 // Caller must provide a non-nil v and close the resp.Body.
-func decodeResponseBodyProtobuf(resp *http.Response, v interface{}) (err error) {
+func decodeResponseBodyProtobuf(resp *http.Response, v interface{}) error {
 	if w, ok := v.(io.Writer); ok {
-		io.Copy(w, resp.Body)
-	} else {
-		//err = protobuf.NewDecoder(resp.Body).Decode(v)
-		//if err == io.EOF {
-		//	err = nil // ignore EOF errors caused by empty response body
-		//}
+		_, err := io.Copy(w, resp.Body)
+		return err
+	}
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("meteor: protobuf: cannot decode into %T, not a proto.Message", v)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
 	}
-	return err
+	return proto.Unmarshal(data, m)
 }
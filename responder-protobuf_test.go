@@ -0,0 +1,98 @@
+package meteor
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestService_ProtobufResponder(t *testing.T) {
+	fault, _ := proto.Marshal(wrapperspb.Int32(42))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/echo":
+			if r.Header.Get(contentType) != protobufContentType {
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+			w.Write(body)
+		case "/fault":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(fault)
+		case "/html":
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte(`<html><body>Bad Gateway</body></html>`))
+		case "/invalid":
+			w.Write([]byte{0xff})
+		}
+	}))
+	defer server.Close()
+
+	echoed, _ := proto.Marshal(wrapperspb.String("sunny"))
+	accepted := func(statusCode int, resp *http.Response) bool {
+		return statusCode == http.StatusBadRequest
+	}
+	tests := []struct {
+		name        string
+		path        string
+		responder   *protobufResponder
+		wantSuccess interface{}
+		wantFailure interface{}
+		wantErr     bool
+		wantAPIErr  bool
+	}{
+		{"success", "echo", ProtobufSuccessResponder(&wrapperspb.StringValue{}), wrapperspb.String("sunny"), nil, false, false},
+		{"writer", "echo", ProtobufSuccessResponder(&bytes.Buffer{}), bytes.NewBuffer(echoed), nil, false, false},
+		{"failure", "fault", ProtobufResponder(&wrapperspb.StringValue{}, &wrapperspb.Int32Value{}), &wrapperspb.StringValue{}, wrapperspb.Int32(42), true, true},
+		{"notProtobufFailure", "html", ProtobufResponder(nil, &wrapperspb.Int32Value{}), nil, nil, true, true},
+		{"isOk", "fault", ProtobufResponder(&wrapperspb.Int32Value{}, nil, accepted), wrapperspb.Int32(42), nil, false, false},
+		{"invalid", "invalid", ProtobufSuccessResponder(&wrapperspb.StringValue{}), nil, nil, true, false},
+		{"notMessage", "echo", ProtobufSuccessResponder(&struct{}{}), nil, nil, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := New().Base(server.URL).Post(tt.path).BodyProtobuf(wrapperspb.String("sunny")).Responder(tt.responder)
+			_, err := svc.Do()
+			if (err != nil) != tt.wantErr || IsAPIError(err) != tt.wantAPIErr {
+				t.Errorf("%v Service.Do() error = %v, wantErr %v, wantAPIErr %v", tt.name, err, tt.wantErr, tt.wantAPIErr)
+			}
+			if tt.wantSuccess != nil && !protobufEqual(tt.wantSuccess, tt.responder.GetSuccess()) {
+				t.Errorf("%v GetSuccess() = %v, want %v", tt.name, tt.responder.GetSuccess(), tt.wantSuccess)
+			}
+			if tt.wantFailure != nil && !protobufEqual(tt.wantFailure, tt.responder.GetFailure()) {
+				t.Errorf("%v GetFailure() = %v, want %v", tt.name, tt.responder.GetFailure(), tt.wantFailure)
+			}
+		})
+	}
+}
+
+// protobufEqual compares messages with proto.Equal and buffers by content.
+func protobufEqual(want, got interface{}) bool {
+	if m, ok := want.(proto.Message); ok {
+		g, ok := got.(proto.Message)
+		return ok && proto.Equal(m, g)
+	}
+	return bytes.Equal(want.(*bytes.Buffer).Bytes(), got.(*bytes.Buffer).Bytes())
+}
+
+func Test_protobufBodyProvider_Body(t *testing.T) {
+	p := protobufBodyProvider{wrapperspb.String("sunny")}
+	if got := p.ContentType(); got != protobufContentType {
+		t.Errorf("protobufBodyProvider.ContentType() = %v, want %v", got, protobufContentType)
+	}
+	body, err := p.Body()
+	if err != nil {
+		t.Fatalf("protobufBodyProvider.Body() error = %v", err)
+	}
+	data, _ := ioutil.ReadAll(body)
+	got := &wrapperspb.StringValue{}
+	if err := proto.Unmarshal(data, got); err != nil || got.GetValue() != "sunny" {
+		t.Errorf("protobufBodyProvider.Body() = %v, %v, want %v", got, err, "sunny")
+	}
+}
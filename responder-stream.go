@@ -0,0 +1,304 @@
+package meteor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"mime"
+	"net/http"
+	"sync"
+)
+
+// ErrStopStream is returned by the callback of a StreamResponder to stop
+// streaming early. The remaining body is not decoded and DoResponse returns
+// a nil error.
+var ErrStopStream = errors.New("meteor: stop stream")
+
+// ndjsonContentTypes are the media types of newline-delimited JSON, streamed
+// value by value even if the values are arrays.
+var ndjsonContentTypes = map[string]bool{
+	"application/x-ndjson": true,
+	"application/ndjson":   true,
+	"application/jsonl":    true,
+	jsonSeqContentType:     true,
+}
+
+// jsonSeqContentType is the media type of JSON text sequences (RFC 7464),
+// whose values start with a record separator.
+const jsonSeqContentType = "application/json-seq"
+
+// recordSeparator starts the values of JSON text sequences.
+const recordSeparator = 0x1E
+
+/** Stream Responder */
+// JSONStreamResponder creates a streaming json response calling each for
+// every element of a top-level JSON array, or every value of newline-delimited
+// JSON (NDJSON), as it is decoded. Failures are decoded into failure.
+func JSONStreamResponder[T any](each func(T) error, failure interface{}, isOKfn ...func(int, *http.Response) bool) *StreamResponder[T] {
+	sr := &StreamResponder[T]{
+		each:    each,
+		failure: failure,
+		isOk:    isOk,
+	}
+
+	if len(isOKfn) > 0 {
+		sr.isOk = isOKfn[0]
+	}
+
+	return sr
+}
+
+// streamer is implemented by Responders reading responses as they arrive,
+// which the Service sends past its cache and coalescer.
+type streamer interface {
+	streams()
+}
+
+// StreamResponder decodes the elements of a JSON response one by one, so
+// large responses are never held in memory, even when the Service has a
+// Cache or a Coalescer, which it skips. The body is read only as fast
+// as the callback returns, and returning ErrStopStream, or any other error,
+// stops reading it; the Service then closes the body.
+type StreamResponder[T any] struct {
+	isOk     func(int, *http.Response) bool
+	each     func(T) error
+	mu       sync.RWMutex
+	request  *http.Request
+	response *http.Response
+	err      error
+	failure  interface{}
+	count    int
+
+	apiErrors apiErrorOptions
+}
+
+// isOk determines whether the HTTP Status Code is an OK Code (200-299)
+// Uses isOK
+func (r *StreamResponder[T]) IsOK(statusCode int, resp *http.Response) bool {
+	if r.isOk != nil {
+		return r.isOk(statusCode, resp)
+	}
+	return isOk(statusCode, resp)
+}
+
+// streams marks StreamResponders as streamers.
+func (r *StreamResponder[T]) streams() {}
+
+// clone creates a fresh StreamResponder for a single call.
+func (r *StreamResponder[T]) clone() Responder {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return &StreamResponder[T]{isOk: r.isOk, each: r.each, failure: freshTarget(r.failure), apiErrors: r.apiErrors}
+}
+
+// fill records the results of the call made with a copy of the responder.
+func (r *StreamResponder[T]) fill(call Responder) {
+	c, ok := call.(*StreamResponder[T])
+	if !ok {
+		return
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.request = c.request
+	r.response = c.response
+	r.err = c.err
+	r.count = c.count
+	r.failure = fillTarget(r.failure, c.failure)
+}
+
+// setAPIErrors sets the APIErrors and Redactor settings of the Service.
+func (r *StreamResponder[T]) setAPIErrors(opts apiErrorOptions) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.apiErrors = opts
+}
+
+// Respond creates the proper response object.
+func (r *StreamResponder[T]) Respond(req *http.Request, resp *http.Response, err error) Responder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.request = req
+	r.response = resp
+	r.err = err
+	r.count = 0
+
+	return r
+}
+
+// DoResponse streams the elements of OK responses to the callback, and
+// decodes other responses into the failure value.
+// Responses that are not OK return an *APIError.
+func (r *StreamResponder[T]) DoResponse() (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.IsOK(r.response.StatusCode, r.response) {
+		raw := captureErrorBody(r.response)
+		if r.failure != nil {
+			decodeResponseBodyJSON(r.response, r.failure)
+		}
+		r.err = failureError(r.apiErrors, r.request, r.response, raw, r.failure)
+		return r.response, r.err
+	}
+
+	r.err = r.stream()
+	if r.err == ErrStopStream {
+		r.err = nil
+	}
+	return r.response, r.err
+}
+
+// stream decodes the body as a top-level JSON array or as a sequence of JSON
+// values, calling each for every element.
+func (r *StreamResponder[T]) stream() error {
+	mediaType, _, _ := mime.ParseMediaType(r.response.Header.Get(contentType))
+	var reader io.Reader = r.response.Body
+	if mediaType == jsonSeqContentType {
+		reader = recordSeparatorReader{reader}
+	}
+	body := bufio.NewReader(reader)
+	dec := json.NewDecoder(body)
+
+	if !ndjsonContentTypes[mediaType] && firstJSONByte(body) == '[' {
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		for dec.More() {
+			if err := r.decode(dec); err != nil {
+				return err
+			}
+		}
+		_, err := dec.Token()
+		return err
+	}
+
+	for {
+		err := r.decode(dec)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// decode decodes the next element and calls each with it.
+func (r *StreamResponder[T]) decode(dec *json.Decoder) error {
+	var v T
+	if err := dec.Decode(&v); err != nil {
+		if err == io.EOF {
+			return err
+		}
+		return fmt.Errorf("meteor: stream element %d: %w", r.count, err)
+	}
+	r.count++
+	if r.each == nil {
+		return nil
+	}
+	return r.each(v)
+}
+
+// recordSeparatorReader drops the record separators of a JSON text sequence,
+// leaving whitespace separated values.
+type recordSeparatorReader struct {
+	r io.Reader
+}
+
+// Read reads from the underlying reader, replacing record separators with
+// spaces.
+func (r recordSeparatorReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	for i := range p[:n] {
+		if p[i] == recordSeparator {
+			p[i] = ' '
+		}
+	}
+	return n, err
+}
+
+// firstJSONByte peeks at the first byte of the body after any whitespace.
+func firstJSONByte(body *bufio.Reader) byte {
+	for {
+		b, err := body.Peek(1)
+		if err != nil {
+			return 0
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			body.ReadByte()
+		default:
+			return b[0]
+		}
+	}
+}
+
+// GetResponse gets the http response.
+func (r *StreamResponder[T]) GetResponse() *http.Response {
+	return r.response
+}
+
+// GetSuccess gets the number of elements streamed.
+func (r *StreamResponder[T]) GetSuccess() interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.count
+}
+
+// GetFailure gets the failure struct.
+func (r *StreamResponder[T]) GetFailure() interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.failure
+}
+
+// GetError gets the error field.
+func (r *StreamResponder[T]) GetError() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.err
+}
+
+// Stream creates a new HTTP request from a child of the Service and returns
+// an iterator over the JSON elements of the response (see
+// JSONStreamResponder). Elements are decoded as the loop asks for them, and
+// breaking out of the loop stops reading and closes the body. Any error
+// creating the request, sending it, or decoding the response is yielded
+// last, with a zero T; failure responses yield an *APIError.
+//
+// Requests go through the Service's middleware, retries and rate limiter,
+// but not its cache nor its coalescer, which would read the whole stream.
+//
+//	for obs, err := range meteor.Stream[Observation](ctx, svc.New().Get("observations")) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func Stream[T any](ctx context.Context, s *Service) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		stopped := false
+		r := JSONStreamResponder(func(v T) error {
+			if !yield(v, nil) {
+				stopped = true
+				return ErrStopStream
+			}
+			return nil
+		}, nil)
+		req, err := s.RequestWithContext(ctx)
+		if err == nil {
+			_, err = s.do(req, r)
+		}
+		if err != nil && !stopped {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package meteor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type streamObservation struct {
+	ID   int     `json:"id"`
+	Temp float64 `json:"temp"`
+}
+
+func TestJSONStreamResponder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/array":
+			w.Write([]byte(` [{"id": 1, "temp": 21.5}, {"id": 2, "temp": 19}] `))
+		case "/ndjson":
+			w.Write([]byte("{\"id\": 1, \"temp\": 21.5}\n{\"id\": 2, \"temp\": 19}\n"))
+		case "/jsonSeq":
+			w.Header().Set(contentType, "application/json-seq")
+			w.Write([]byte("\x1e{\"id\": 1, \"temp\": 21.5}\n\x1e{\"id\": 2, \"temp\": 19}\n"))
+		case "/ndjsonArrays":
+			w.Header().Set(contentType, "application/x-ndjson")
+			w.Write([]byte("[{\"id\": 1}]\n[{\"id\": 2}]\n"))
+		case "/empty":
+		case "/truncated":
+			w.Write([]byte(`[{"id": 1}, {"id": `))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"text": "bad request"}`))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		path        string
+		want        []streamObservation
+		wantFailure interface{}
+		wantErr     bool
+	}{
+		{"array", "array", []streamObservation{{1, 21.5}, {2, 19}}, nil, false},
+		{"ndjson", "ndjson", []streamObservation{{1, 21.5}, {2, 19}}, nil, false},
+		{"jsonSeq", "jsonSeq", []streamObservation{{1, 21.5}, {2, 19}}, nil, false},
+		{"empty", "empty", nil, nil, false},
+		{"truncated", "truncated", []streamObservation{{1, 0}}, nil, true},
+		{"failure", "failure", nil, &FakeModel{Text: "bad request"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []streamObservation
+			r := JSONStreamResponder(func(v streamObservation) error {
+				got = append(got, v)
+				return nil
+			}, &FakeModel{})
+			_, err := New().Base(server.URL).Path(tt.path).Responder(r).Do()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("%v Service.Do() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if !assert.Equal(t, tt.want, got) {
+				t.Errorf("%v streamed = %v, want %v", tt.name, got, tt.want)
+			}
+			assert.Equal(t, len(tt.want), r.GetSuccess())
+			if tt.wantFailure != nil && !assert.Equal(t, tt.wantFailure, r.GetFailure()) {
+				t.Errorf("%v GetFailure() = %v, want %v", tt.name, r.GetFailure(), tt.wantFailure)
+			}
+		})
+	}
+
+	// NDJSON content types stream arrays as values
+	var arrays [][]streamObservation
+	r := JSONStreamResponder(func(v []streamObservation) error {
+		arrays = append(arrays, v)
+		return nil
+	}, nil)
+	if _, err := New().Base(server.URL).Path("ndjsonArrays").Responder(r).Do(); err != nil {
+		t.Fatalf("Service.Do() error = %v", err)
+	}
+	assert.Equal(t, [][]streamObservation{{{1, 0}}, {{2, 0}}}, arrays)
+}
+
+func TestStream(t *testing.T) {
+	// an endless stream, written only as fast as it is read
+	closed := make(chan struct{}, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/failure" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		defer func() { closed <- struct{}{} }()
+		w.Write([]byte("["))
+		for i := 1; ; i++ {
+			if i > 1 {
+				w.Write([]byte(","))
+			}
+			if _, err := io.WriteString(w, `{"id": `+strings.Repeat(" ", 4096)+string(rune('0'+i%10))+`}`); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			default:
+			}
+		}
+	}))
+	defer server.Close()
+
+	// the cache and the coalescer, which read whole bodies, are skipped
+	services := map[string]*Service{
+		"plain":    New().Base(server.URL).Path("endless"),
+		"buffered": New().Base(server.URL).Path("endless").Cache(NewCache(NewLRUCacheStore(10))).Coalesce(NewCoalescer()),
+	}
+	for name, svc := range services {
+		var ids []int
+		for obs, err := range Stream[streamObservation](context.Background(), svc) {
+			if err != nil {
+				t.Fatalf("%v Stream() error = %v", name, err)
+			}
+			ids = append(ids, obs.ID)
+			if len(ids) == 3 {
+				break
+			}
+		}
+		assert.Equal(t, []int{1, 2, 3}, ids)
+
+		// breaking out of the loop closes the body
+		select {
+		case <-closed:
+		case <-time.After(5 * time.Second):
+			t.Errorf("%v Stream() did not close the body", name)
+		}
+	}
+
+	// so does a StreamResponder sent by Do
+	var ids []int
+	r := JSONStreamResponder(func(obs streamObservation) error {
+		ids = append(ids, obs.ID)
+		if len(ids) == 3 {
+			return ErrStopStream
+		}
+		return nil
+	}, nil)
+	if _, err := services["buffered"].New().Responder(r).Do(); err != nil {
+		t.Fatalf("Service.Do() error = %v", err)
+	}
+	assert.Equal(t, []int{1, 2, 3}, ids)
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Errorf("Service.Do() did not close the body")
+	}
+
+	var errs []error
+	for _, err := range Stream[streamObservation](context.Background(), New().Base(server.URL).Path("failure")) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || !IsAPIError(errs[0]) {
+		t.Errorf("Stream() errors = %v, want an *APIError", errs)
+	}
+	var apiErr *APIError
+	if errors.As(errs[0], &apiErr) && apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Stream() status = %v, want %v", apiErr.StatusCode, http.StatusServiceUnavailable)
+	}
+}
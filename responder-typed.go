@@ -0,0 +1,98 @@
+package meteor
+
+import (
+	"context"
+	"net/http"
+)
+
+/** Typed Responder */
+// TypedResponder is a Responder that decodes into typed success and failure
+// values, so results need no type assertions.
+type TypedResponder[S, F any] struct {
+	Responder
+	success *S
+	failure *F
+}
+
+// JSONResponderOf creates a json response decoding into new S and F values.
+func JSONResponderOf[S, F any](isOKfn ...func(int, *http.Response) bool) *TypedResponder[S, F] {
+	success, failure := new(S), new(F)
+	return &TypedResponder[S, F]{
+		Responder: JSONResponder(success, failure, isOKfn...),
+		success:   success,
+		failure:   failure,
+	}
+}
+
+// JSONSuccessResponderOf creates a json response decoding successes into a new S value.
+func JSONSuccessResponderOf[S any]() *TypedResponder[S, struct{}] {
+	success := new(S)
+	return &TypedResponder[S, struct{}]{
+		Responder: JSONSuccessResponder(success),
+		success:   success,
+		failure:   new(struct{}),
+	}
+}
+
+// clone creates a TypedResponder decoding into fresh values for a single
+// call.
+func (r *TypedResponder[S, F]) clone() Responder {
+	c := &TypedResponder[S, F]{Responder: callResponder(r.Responder), success: r.success, failure: r.failure}
+	if success, ok := c.Responder.GetSuccess().(*S); ok {
+		c.success = success
+	}
+	if failure, ok := c.Responder.GetFailure().(*F); ok {
+		c.failure = failure
+	}
+	return c
+}
+
+// fill records the results of the call made with a copy of the responder.
+func (r *TypedResponder[S, F]) fill(call Responder) {
+	if c, ok := call.(*TypedResponder[S, F]); ok {
+		fillResponder(r.Responder, c.Responder)
+	}
+}
+
+// setAPIErrors sets the APIErrors and Redactor settings of the Service on
+// the wrapped Responder.
+func (r *TypedResponder[S, F]) setAPIErrors(opts apiErrorOptions) {
+	setAPIErrors(r.Responder, opts)
+}
+
+// Success gets the typed success value.
+func (r *TypedResponder[S, F]) Success() S {
+	return *r.success
+}
+
+// Failure gets the typed failure value.
+func (r *TypedResponder[S, F]) Failure() F {
+	return *r.failure
+}
+
+// Receive creates a new HTTP request from a child of the Service and returns
+// the typed results. Success responses (2XX) are JSON decoded into S and other
+// responses into F. Any error creating the request, sending it, or decoding
+// the response is returned.
+// The Service's responder and ResponderFunc are not used nor modified.
+func Receive[S, F any](ctx context.Context, s *Service) (S, F, *http.Response, error) {
+	r := JSONResponderOf[S, F]()
+	resp, err := receive(ctx, s, r)
+	return r.Success(), r.Failure(), resp, err
+}
+
+// ReceiveSuccess is like Receive but only decodes success responses.
+func ReceiveSuccess[S any](ctx context.Context, s *Service) (S, *http.Response, error) {
+	r := JSONSuccessResponderOf[S]()
+	resp, err := receive(ctx, s, r)
+	return r.Success(), resp, err
+}
+
+// receive sends the request of the Service and decodes it with r.
+func receive(ctx context.Context, s *Service, r Responder) (*http.Response, error) {
+	req, err := s.RequestWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.do(req, r)
+}
@@ -0,0 +1,95 @@
+package meteor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReceive(t *testing.T) {
+	successBody := `{"a": "a success", "b": "another success", "c": "2017-11-01T22:08:41+00:00"}`
+	failBody := `{"errors": [{"code": "EAE:INV-0001","message": "Invalid request"}],"metadata": {"status_code": 400,"transaction_id": "1429140092945:1801695336"},"success": false}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/success" {
+			w.Write([]byte(successBody))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(failBody))
+	}))
+	defer server.Close()
+
+	svc := New().Base(server.URL)
+	factory := svc.New().ResponderFunc(func() Responder { return JSONResponder(&wxResponse{}, &wxErr{}) })
+	tests := []struct {
+		name        string
+		s           *Service
+		path        string
+		wantSuccess wxResponse
+		wantFailure wxErr
+		wantErr     bool
+	}{
+		{"success", svc, "success", *wantedSuccess, wxErr{}, false},
+		{"failure", svc, "failure", wxResponse{}, *wantedFailure, true},
+		{"factorySuccess", factory, "success", *wantedSuccess, wxErr{}, false},
+		{"factoryFailure", factory, "failure", wxResponse{}, *wantedFailure, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			success, failure, resp, err := Receive[wxResponse, wxErr](context.Background(), tt.s.New().Path(tt.path))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%v Receive() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if resp == nil {
+				t.Fatalf("%v Receive() response = nil", tt.name)
+			}
+			if !assert.Equal(t, tt.wantSuccess, success) {
+				t.Errorf("%v Receive() success = %v, want %v", tt.name, success, tt.wantSuccess)
+			}
+			if !assert.Equal(t, tt.wantFailure, failure) {
+				t.Errorf("%v Receive() failure = %v, want %v", tt.name, failure, tt.wantFailure)
+			}
+		})
+	}
+
+	if _, ok := svc.GetResponder().(*genericResponder); !ok {
+		t.Errorf("Receive() modified the Service responder = %T", svc.GetResponder())
+	}
+}
+
+func TestReceiveSuccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"text": "a", "temperature": 21.5}, {"text": "b"}]`))
+	}))
+	defer server.Close()
+
+	want := []FakeModel{{Text: "a", Temperature: 21.5}, {Text: "b"}}
+	svc := New().Base(server.URL)
+	for _, s := range []*Service{svc, svc.New().ResponderFunc(func() Responder { return BinarySuccessResponder() })} {
+		got, _, err := ReceiveSuccess[[]FakeModel](context.Background(), s)
+		if err != nil {
+			t.Fatalf("ReceiveSuccess() error = %v", err)
+		}
+		if !assert.Equal(t, want, got) {
+			t.Errorf("ReceiveSuccess() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestJSONResponderOf(t *testing.T) {
+	r := JSONResponderOf[wxResponse, wxErr](func(statusCode int, resp *http.Response) bool {
+		return statusCode == http.StatusAccepted
+	})
+	if !r.IsOK(http.StatusAccepted, nil) || r.IsOK(http.StatusOK, nil) {
+		t.Errorf("JSONResponderOf() did not use the isOK function")
+	}
+	if _, ok := r.GetSuccess().(*wxResponse); !ok {
+		t.Errorf("JSONResponderOf().GetSuccess() = %T, want *wxResponse", r.GetSuccess())
+	}
+	if _, ok := r.GetFailure().(*wxErr); !ok {
+		t.Errorf("JSONResponderOf().GetFailure() = %T, want *wxErr", r.GetFailure())
+	}
+}
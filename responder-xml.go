@@ -0,0 +1,150 @@
+package meteor
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+)
+
+/** XML Responder */
+// XMLSuccessResponder creates an xml response with Success.
+func XMLSuccessResponder(success interface{}) *xmlResponder {
+	return &xmlResponder{
+		Success: success,
+		isOk:    isOk,
+	}
+}
+
+// XMLResponder creates an xml response with Failure and Success.
+func XMLResponder(success, failure interface{}, isOKfn ...func(int, *http.Response) bool) *xmlResponder {
+	xr := &xmlResponder{
+		Failure: failure,
+		Success: success,
+		isOk:    isOk,
+	}
+
+	if len(isOKfn) > 0 {
+		xr.isOk = isOKfn[0]
+	}
+
+	return xr
+}
+
+// xmlResponder
+type xmlResponder responder
+
+// isOk determines whether the HTTP Status Code is an OK Code (200-299)
+// Uses isOK
+func (r *xmlResponder) IsOK(statusCode int, resp *http.Response) bool {
+	if r.isOk != nil {
+		return r.isOk(statusCode, resp)
+	}
+	return isOk(statusCode, resp)
+}
+
+// clone creates a fresh xmlResponder for a single call.
+func (r *xmlResponder) clone() Responder {
+	return (*xmlResponder)((*responder)(r).copyTo(&responder{}))
+}
+
+// fill records the results of the call made with a copy of the responder.
+func (r *xmlResponder) fill(call Responder) {
+	if c, ok := call.(*xmlResponder); ok {
+		(*responder)(r).fillFrom((*responder)(c))
+	}
+}
+
+// setAPIErrors sets the APIErrors and Redactor settings of the Service.
+func (r *xmlResponder) setAPIErrors(opts apiErrorOptions) {
+	(*responder)(r).setAPIErrors(opts)
+}
+
+// Respond creates the proper response object.
+func (r *xmlResponder) Respond(req *http.Request, resp *http.Response, err error) Responder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Request = req
+	r.Response = resp
+	r.Error = err
+
+	return r
+}
+
+// DoResponse does the actual response decoding from xml.
+// Responses that are not OK return an *APIError.
+func (r *xmlResponder) DoResponse() (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ok := r.IsOK(r.Response.StatusCode, r.Response)
+	var raw []byte
+	if !ok {
+		raw = captureErrorBody(r.Response)
+	}
+	if r.Success != nil || r.Failure != nil {
+		r.Error = decodeResponseXML(r.IsOK, r.Response, r.Success, r.Failure)
+	}
+	if !ok && r.Error == nil {
+		r.Error = failureError(r.apiErrors, r.Request, r.Response, raw, r.Failure)
+	}
+	return r.Response, r.Error
+}
+
+// GetResponse gets the http response.
+func (r *xmlResponder) GetResponse() *http.Response {
+	return r.Response
+}
+
+// GetSuccess gets the success struct.
+func (r *xmlResponder) GetSuccess() interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.Success
+}
+
+// GetFailure gets the failure struct.
+func (r *xmlResponder) GetFailure() interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.Failure
+}
+
+// GetError gets the error field.
+func (r *xmlResponder) GetError() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.Error
+}
+
+// decodeResponseXML decodes response Body into the value pointed to by
+// successV if the response is a success (2XX) or into the value pointed to by
+// failureV otherwise. If the successV or failureV argument to decode into is
+// nil, decoding is skipped. Failures that are not XML, such as an HTML error
+// page, are not an error, so the *APIError with the raw body is returned.
+// Caller is responsible for closing the resp.Body.
+func decodeResponseXML(okFn func(int, *http.Response) bool, resp *http.Response, successV, failureV interface{}) error {
+	if okFn(resp.StatusCode, resp) {
+		if successV != nil {
+			return decodeResponseBodyXML(resp, successV)
+		}
+	} else if failureV != nil {
+		decodeResponseBodyXML(resp, failureV)
+	}
+	return nil
+}
+
+// decodeResponseBodyXML XML decodes a Response Body into the value pointed
+// to by v, or copies it if v is an io.Writer.
+// Caller must provide a non-nil v and close the resp.Body.
+func decodeResponseBodyXML(resp *http.Response, v interface{}) (err error) {
+	if w, ok := v.(io.Writer); ok {
+		_, err = io.Copy(w, resp.Body)
+		return err
+	}
+	err = xml.NewDecoder(resp.Body).Decode(v)
+	if err == io.EOF {
+		err = nil // ignore EOF errors caused by empty response body
+	}
+	return err
+}
@@ -0,0 +1,80 @@
+package meteor
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type xmlForecast struct {
+	XMLName xml.Name `xml:"forecast"`
+	Text    string   `xml:"text"`
+}
+
+type xmlFault struct {
+	XMLName xml.Name `xml:"fault"`
+	Code    int      `xml:"code"`
+}
+
+func TestService_XMLResponder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/echo":
+			if r.Header.Get(contentType) != xmlContentType {
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+			w.Write(body)
+		case "/fault":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`<fault><code>42</code></fault>`))
+		case "/html":
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte(`<html><body>Bad Gateway</html>`))
+		case "/invalid":
+			w.Write([]byte(`<forecast><text>`))
+		}
+	}))
+	defer server.Close()
+
+	accepted := func(statusCode int, resp *http.Response) bool {
+		return statusCode == http.StatusBadRequest
+	}
+	tests := []struct {
+		name        string
+		path        string
+		responder   *xmlResponder
+		wantSuccess interface{}
+		wantFailure interface{}
+		wantErr     bool
+		wantAPIErr  bool
+	}{
+		{"success", "echo", XMLSuccessResponder(&xmlForecast{}), &xmlForecast{XMLName: xml.Name{Local: "forecast"}, Text: "sunny"}, nil, false, false},
+		{"writer", "echo", XMLSuccessResponder(&bytes.Buffer{}), bytes.NewBufferString(`<forecast><text>sunny</text></forecast>`), nil, false, false},
+		{"failure", "fault", XMLResponder(&xmlForecast{}, &xmlFault{}), &xmlForecast{}, &xmlFault{XMLName: xml.Name{Local: "fault"}, Code: 42}, true, true},
+		{"notXMLFailure", "html", XMLResponder(nil, &xmlFault{}), nil, &xmlFault{}, true, true},
+		{"isOk", "fault", XMLResponder(&xmlFault{}, nil, accepted), &xmlFault{XMLName: xml.Name{Local: "fault"}, Code: 42}, nil, false, false},
+		{"invalid", "invalid", XMLSuccessResponder(&xmlForecast{}), nil, nil, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := New().Base(server.URL).Post(tt.path).BodyXML(&xmlForecast{Text: "sunny"}).Responder(tt.responder)
+			_, err := svc.Do()
+			if (err != nil) != tt.wantErr || IsAPIError(err) != tt.wantAPIErr {
+				t.Errorf("%v Service.Do() error = %v, wantErr %v, wantAPIErr %v", tt.name, err, tt.wantErr, tt.wantAPIErr)
+			}
+			if tt.wantSuccess != nil && !assert.Equal(t, tt.wantSuccess, tt.responder.GetSuccess()) {
+				t.Errorf("%v GetSuccess() = %v, want %v", tt.name, tt.responder.GetSuccess(), tt.wantSuccess)
+			}
+			if tt.wantFailure != nil && !assert.Equal(t, tt.wantFailure, tt.responder.GetFailure()) {
+				t.Errorf("%v GetFailure() = %v, want %v", tt.name, tt.responder.GetFailure(), tt.wantFailure)
+			}
+		})
+	}
+}
@@ -3,8 +3,12 @@ package meteor
 //go:generate moq -out responder_mocks_test.go . Responder
 
 import (
+	"io"
 	"net/http"
+	"reflect"
 	"sync"
+
+	"google.golang.org/protobuf/proto"
 )
 
 // ResponseProvider provides a modifier for the response.
@@ -18,6 +22,97 @@ type Responder interface {
 	GetError() error
 }
 
+// ResponderFunc creates a fresh Responder for a single call.
+type ResponderFunc func() Responder
+
+// cloner is implemented by Responders that can be copied, so every call
+// gets a fresh Responder instead of sharing the Service's one.
+type cloner interface {
+	clone() Responder
+}
+
+// callResponder returns a fresh copy of the responder for a single call if
+// it can be copied, or the responder itself.
+func callResponder(r Responder) Responder {
+	if c, ok := r.(cloner); ok {
+		return c.clone()
+	}
+	return r
+}
+
+// filler is implemented by Responders whose copies record the results of
+// a call back into them, so a responder set on a Service holds the results
+// of its last synchronous Do.
+type filler interface {
+	fill(call Responder)
+}
+
+// fillResponder records the results of the call made with a copy of r back
+// into r, if r can be filled.
+func fillResponder(r, call Responder) {
+	if f, ok := r.(filler); ok && r != call {
+		f.fill(call)
+	}
+}
+
+// freshTarget returns a pointer to a copy of the value v points to, so
+// every call decodes into a value of its own. io.Writers, which are sinks
+// rather than values, and values that are not pointers are shared.
+func freshTarget(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	if _, ok := v.(io.Writer); ok {
+		return v
+	}
+	if m, ok := v.(proto.Message); ok {
+		return proto.Clone(m)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return v
+	}
+	fresh := reflect.New(rv.Type().Elem())
+	fresh.Elem().Set(rv.Elem())
+	return fresh.Interface()
+}
+
+// fillTarget copies the value decoded by a call, src, into the value dst
+// points to and returns dst. If src is not a pointer of the same type, e.g.
+// a Responder replaced its target, src is returned.
+func fillTarget(dst, src interface{}) interface{} {
+	d, v := reflect.ValueOf(dst), reflect.ValueOf(src)
+	if !d.IsValid() || !v.IsValid() || d.Kind() != reflect.Ptr || d.IsNil() || v.Kind() != reflect.Ptr || v.IsNil() || d.Type() != v.Type() {
+		return src
+	}
+	if d.Pointer() == v.Pointer() {
+		return dst
+	}
+	if m, ok := dst.(proto.Message); ok {
+		proto.Reset(m)
+		proto.Merge(m, src.(proto.Message))
+		return dst
+	}
+	d.Elem().Set(v.Elem())
+	return dst
+}
+
+// apiErrorSetter is implemented by Responders returning an *APIError for
+// responses that are not OK, so they follow the APIErrors and Redactor
+// settings of the Service doing the call.
+type apiErrorSetter interface {
+	setAPIErrors(apiErrorOptions)
+}
+
+// setAPIErrors sets the APIErrors and Redactor settings of the responder if
+// it returns APIErrors.
+func setAPIErrors(r Responder, opts apiErrorOptions) Responder {
+	if s, ok := r.(apiErrorSetter); ok {
+		s.setAPIErrors(opts)
+	}
+	return r
+}
+
 // responder
 type responder struct {
 	isOk     func(int, *http.Response) bool
@@ -27,6 +122,41 @@ type responder struct {
 	Error    error
 	Failure  interface{}
 	Success  interface{}
+
+	apiErrors apiErrorOptions
+}
+
+// copyTo copies the configuration of the responder, but not the state of its
+// last call, into c. c decodes into fresh success and failure values.
+func (r *responder) copyTo(c *responder) *responder {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c.isOk = r.isOk
+	c.Failure = freshTarget(r.Failure)
+	c.Success = freshTarget(r.Success)
+	c.apiErrors = r.apiErrors
+	return c
+}
+
+// fillFrom records the request, response and error of the call made with
+// the copy c, and copies its decoded values into the responder's.
+func (r *responder) fillFrom(c *responder) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Request = c.Request
+	r.Response = c.Response
+	r.Error = c.Error
+	r.Success = fillTarget(r.Success, c.Success)
+	r.Failure = fillTarget(r.Failure, c.Failure)
+}
+
+// setAPIErrors sets the APIErrors and Redactor settings of the Service.
+func (r *responder) setAPIErrors(opts apiErrorOptions) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.apiErrors = opts
 }
 
 // isOk determines whether the HTTP Status Code is an OK Code (200-299)
@@ -0,0 +1,271 @@
+package meteor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultMaxAttempts is the default number of attempts of a RetryPolicy.
+	DefaultMaxAttempts = 3
+	// DefaultMinBackoff is the default backoff before the first retry.
+	DefaultMinBackoff = 100 * time.Millisecond
+	// DefaultMaxBackoff is the default maximum backoff between attempts.
+	DefaultMaxBackoff = 5 * time.Second
+)
+
+// RetryPolicy determines whether and when a failed request is retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	// A value of 1 or less disables retries.
+	MaxAttempts int
+
+	// MinBackoff is the backoff before the first retry. It doubles on every
+	// following retry.
+	MinBackoff time.Duration
+
+	// MaxBackoff caps the backoff between attempts, including the wait
+	// requested by a Retry-After header.
+	MaxBackoff time.Duration
+
+	// Jitter is the fraction, between 0 and 1, of the backoff that is randomized.
+	Jitter float64
+
+	// RetryableStatusCodes are the response status codes that are retried.
+	RetryableStatusCodes []int
+
+	// RetryableError reports whether an error returned by the Doer is retried.
+	// If nil, IsRetryableError is used.
+	RetryableError func(error) bool
+
+	// RetryAfter honors the Retry-After header of retried responses.
+	RetryAfter bool
+
+	// RetryNonIdempotent also retries requests whose method is not
+	// idempotent, such as POST and PATCH, which the server may then apply
+	// more than once. Requests with an Idempotency-Key header are retried
+	// regardless.
+	RetryNonIdempotent bool
+}
+
+// NewRetryPolicy returns a RetryPolicy with exponential backoff and jitter
+// that retries connection errors and 429, 502, 503 and 504 responses,
+// honoring Retry-After headers. Only idempotent requests are retried: GET,
+// HEAD, OPTIONS, PUT and DELETE requests, and requests with an
+// Idempotency-Key header.
+func NewRetryPolicy(maxAttempts ...int) *RetryPolicy {
+	attempts := DefaultMaxAttempts
+	if len(maxAttempts) > 0 && maxAttempts[0] > 0 {
+		attempts = maxAttempts[0]
+	}
+	return &RetryPolicy{
+		MaxAttempts: attempts,
+		MinBackoff:  DefaultMinBackoff,
+		MaxBackoff:  DefaultMaxBackoff,
+		Jitter:      0.5,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RetryAfter: true,
+	}
+}
+
+// RetryError is returned when a request still fails after all the attempts
+// of a RetryPolicy. It wraps the error of the last attempt.
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+// Error implements the error interface.
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("meteor: request failed after %d attempt(s): %v", e.Attempts, e.Err)
+}
+
+// Unwrap returns the error of the last attempt.
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// retryAttemptsKey is the context key of the attempt count of a request.
+type retryAttemptsKey struct{}
+
+// RetryAttempts returns the number of attempts a RetryPolicy made for the
+// request of the response, or 0 if no policy was used. The count is kept on
+// the request context, leaving the response headers untouched.
+func RetryAttempts(resp *http.Response) int {
+	if resp == nil || resp.Request == nil {
+		return 0
+	}
+	if n, ok := resp.Request.Context().Value(retryAttemptsKey{}).(*int); ok {
+		return *n
+	}
+	return 0
+}
+
+// IsRetryableError reports whether err is a transient network error, such
+// as a timeout or a reset connection.
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isRetryableError reports whether the error is retried by the policy.
+func (p *RetryPolicy) isRetryableError(err error) bool {
+	if p.RetryableError != nil {
+		return p.RetryableError(err)
+	}
+	return IsRetryableError(err)
+}
+
+// idempotencyKeyHeader marks a request that the server applies only once,
+// however many times it is sent.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// isRetryableRequest reports whether the policy retries the request: its
+// method is idempotent, it has an Idempotency-Key, or the policy retries
+// every method.
+func (p *RetryPolicy) isRetryableRequest(req *http.Request) bool {
+	if p.RetryNonIdempotent || req.Header.Get(idempotencyKeyHeader) != "" {
+		return true
+	}
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isRetryableStatus reports whether the status code is retried by the policy.
+func (p *RetryPolicy) isRetryableStatus(statusCode int) bool {
+	for _, code := range p.RetryableStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the wait before the given retry (starting at 1).
+func (p *RetryPolicy) backoff(retry int, resp *http.Response) time.Duration {
+	wait := p.MinBackoff
+	for i := 1; i < retry && (p.MaxBackoff <= 0 || wait < p.MaxBackoff); i++ {
+		wait *= 2
+	}
+	if p.Jitter > 0 && wait > 0 {
+		jitter := time.Duration(p.Jitter * float64(wait))
+		wait = wait - jitter + time.Duration(rand.Int63n(int64(2*jitter)+1))
+	}
+	if p.RetryAfter && resp != nil {
+		if after, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok && after > wait {
+			wait = after
+		}
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	return wait
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date), true
+	}
+	return 0, false
+}
+
+// retryDoer sends requests with the given Doer following a RetryPolicy.
+type retryDoer struct {
+	doer   Doer
+	policy *RetryPolicy
+}
+
+// Do sends the request, retrying it according to the policy. Request bodies
+// are rewound between attempts with http.Request.GetBody; requests whose body
+// cannot be rewound are sent once.
+// Implements Doer interface
+func (d retryDoer) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	attempt := 1
+	req = req.WithContext(context.WithValue(ctx, retryAttemptsKey{}, &attempt))
+	for {
+		resp, err := d.doer.Do(req)
+
+		retry := attempt < d.policy.MaxAttempts && ctx.Err() == nil && d.policy.isRetryableRequest(req) && rewindable(req)
+		if err != nil {
+			retry = retry && d.policy.isRetryableError(err)
+		} else {
+			retry = retry && d.policy.isRetryableStatus(resp.StatusCode)
+		}
+		if !retry {
+			if err != nil {
+				return resp, &RetryError{Attempts: attempt, Err: err}
+			}
+			return resp, nil
+		}
+
+		wait := d.policy.backoff(attempt, resp)
+		if resp != nil {
+			io.CopyN(ioutil.Discard, resp.Body, NBytes)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, &RetryError{Attempts: attempt, Err: ctx.Err()}
+		case <-timer.C:
+		}
+
+		if req, err = rewindBody(req); err != nil {
+			return nil, &RetryError{Attempts: attempt, Err: err}
+		}
+		attempt++
+	}
+}
+
+// rewindable reports whether the request body can be sent again.
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewindBody returns a copy of the request with a fresh body from GetBody.
+func rewindBody(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.Body = body
+	return r, nil
+}
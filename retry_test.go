@@ -0,0 +1,350 @@
+package meteor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestRetryPolicy(maxAttempts int) *RetryPolicy {
+	policy := NewRetryPolicy(maxAttempts)
+	policy.MinBackoff = time.Millisecond
+	policy.MaxBackoff = 10 * time.Millisecond
+	return policy
+}
+
+func TestService_Retry(t *testing.T) {
+	policy := NewRetryPolicy()
+	tests := []struct {
+		name string
+		s    *Service
+		want *RetryPolicy
+	}{
+		{"default", New(), nil},
+		{"policy", New().Retry(policy), policy},
+		{"policyNew", New().Retry(policy).New(), policy},
+		{"policyReset", New().Retry(policy).Reset(), nil},
+		{"policyNil", New().Retry(policy).Retry(nil), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.retryPolicy; got != tt.want {
+				t.Errorf("%v Service.Retry() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_Do_retry(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		maxAttempts  int
+		wantStatus   int
+		wantAttempts int
+	}{
+		{"success", []int{http.StatusOK}, 3, http.StatusOK, 1},
+		{"retried", []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK}, 3, http.StatusOK, 3},
+		{"exhausted", []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK}, 2, http.StatusServiceUnavailable, 2},
+		{"notRetryable", []int{http.StatusNotFound, http.StatusOK}, 3, http.StatusNotFound, 1},
+		{"disabled", []int{http.StatusServiceUnavailable, http.StatusOK}, 1, http.StatusServiceUnavailable, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var calls int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				w.WriteHeader(tt.statuses[calls])
+				calls++
+			}))
+			defer server.Close()
+
+			resp, err := New().Base(server.URL).Retry(newTestRetryPolicy(tt.maxAttempts)).Do()
+			if err != nil {
+				t.Fatalf("%v Service.Do() error = %v", tt.name, err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%v Service.Do() StatusCode = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if got := RetryAttempts(resp); got != tt.wantAttempts {
+				t.Errorf("%v RetryAttempts() = %v, want %v", tt.name, got, tt.wantAttempts)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if calls != tt.wantAttempts {
+				t.Errorf("%v server calls = %v, want %v", tt.name, calls, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestService_Do_retryBody(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		s    *Service
+		want string
+	}{
+		{"json", New().Put(server.URL).BodyJSON(jsonBody), `{"title":"Test title","body":"Some issue"}` + "\n"},
+		{"form", New().Post(server.URL).Set("Idempotency-Key", "a1").BodyForm(formBody), "status=writing+some+Go"},
+		{"seeker", New().Put(server.URL).Body(&seekOnlyReader{strings.NewReader("seeked body")}), "seeked body"},
+		{"provider", New().Put(server.URL).BodyProvider(rewindableProvider{&BodyProviderMock{
+			BodyFunc:        func() (io.Reader, error) { return ioutil.NopCloser(strings.NewReader("raw body")), nil },
+			ContentTypeFunc: func() string { return textContentType },
+		}}), "raw body"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			bodies = nil
+			mu.Unlock()
+			resp, err := tt.s.Retry(newTestRetryPolicy(3)).Do()
+			if err != nil {
+				t.Fatalf("%v Service.Do() error = %v", tt.name, err)
+			}
+			if got := RetryAttempts(resp); got != 3 {
+				t.Errorf("%v RetryAttempts() = %v, want %v", tt.name, got, 3)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for i, body := range bodies {
+				if body != tt.want {
+					t.Errorf("%v attempt %v body = %q, want %q", tt.name, i+1, body, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestService_Do_retryNotRewindable(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		s    *Service
+	}{
+		{"reader", New().Put(server.URL).Body(io.MultiReader(strings.NewReader("one-shot body")))},
+		{"provider", New().Put(server.URL).BodyProvider(&BodyProviderMock{
+			BodyFunc:        func() (io.Reader, error) { return io.MultiReader(strings.NewReader("one-shot body")), nil },
+			ContentTypeFunc: func() string { return textContentType },
+		})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			bodies = nil
+			mu.Unlock()
+			resp, err := tt.s.Retry(newTestRetryPolicy(3)).APIErrors(false).Do()
+			if err != nil {
+				t.Fatalf("%v Service.Do() error = %v", tt.name, err)
+			}
+			if got := RetryAttempts(resp); got != 1 {
+				t.Errorf("%v RetryAttempts() = %v, want %v", tt.name, got, 1)
+			}
+			if _, ok := resp.Header[http.CanonicalHeaderKey("X-Meteor-Retry-Attempts")]; ok {
+				t.Errorf("%v response headers = %v, want the server's headers only", tt.name, resp.Header)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if len(bodies) != 1 {
+				t.Errorf("%v server calls = %v, want %v", tt.name, len(bodies), 1)
+			}
+			for i, body := range bodies {
+				if body != "one-shot body" {
+					t.Errorf("%v attempt %v body = %q, want %q", tt.name, i+1, body, "one-shot body")
+				}
+			}
+		})
+	}
+}
+
+func TestService_Do_retryMethods(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	nonIdempotent := newTestRetryPolicy(3)
+	nonIdempotent.RetryNonIdempotent = true
+	tests := []struct {
+		name      string
+		s         *Service
+		wantCalls int32
+	}{
+		{"get", New().Get(server.URL), 3},
+		{"delete", New().Delete(server.URL), 3},
+		{"post", New().Post(server.URL).BodyJSON(jsonBody), 1},
+		{"patch", New().Patch(server.URL).BodyJSON(jsonBody), 1},
+		{"postIdempotencyKey", New().Post(server.URL).Set("Idempotency-Key", "a1").BodyJSON(jsonBody), 3},
+		{"postNonIdempotent", New().Post(server.URL).BodyJSON(jsonBody).Retry(nonIdempotent), 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&calls, 0)
+			if tt.s.retryPolicy == nil {
+				tt.s.Retry(newTestRetryPolicy(3))
+			}
+			resp, err := tt.s.APIErrors(false).Do()
+			if err != nil {
+				t.Fatalf("%v Service.Do() error = %v", tt.name, err)
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("%v server calls = %v, want %v", tt.name, got, tt.wantCalls)
+			}
+			if got := RetryAttempts(resp); got != int(tt.wantCalls) {
+				t.Errorf("%v RetryAttempts() = %v, want %v", tt.name, got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// seekOnlyReader hides every method of a reader but Read and Seek.
+type seekOnlyReader struct {
+	r io.ReadSeeker
+}
+
+func (r *seekOnlyReader) Read(p []byte) (int, error) {
+	return r.r.Read(p)
+}
+
+func (r *seekOnlyReader) Seek(offset int64, whence int) (int64, error) {
+	return r.r.Seek(offset, whence)
+}
+
+// rewindableProvider is a BodyProvider returning a fresh body on every call.
+type rewindableProvider struct {
+	*BodyProviderMock
+}
+
+func (p rewindableProvider) Rewindable() bool {
+	return true
+}
+
+func TestService_Do_retryError(t *testing.T) {
+	var mu sync.Mutex
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer server.Close()
+
+	_, err := New().Base(server.URL).Retry(newTestRetryPolicy(3)).Do()
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("Service.Do() error = %v, want *RetryError", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if retryErr.Attempts != 3 || calls != 3 {
+		t.Errorf("RetryError.Attempts = %v, server calls = %v, want %v", retryErr.Attempts, calls, 3)
+	}
+}
+
+func TestService_Do_retryContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	policy := NewRetryPolicy(3)
+	policy.MaxBackoff = time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := New().Base(server.URL).Retry(policy).DoContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Service.DoContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Service.DoContext() waited %v, want the context to stop the Retry-After wait", elapsed)
+	}
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	policy := &RetryPolicy{MinBackoff: time.Second, MaxBackoff: 5 * time.Second, RetryAfter: true}
+	retryAfter := func(value string) *http.Response {
+		return &http.Response{Header: http.Header{"Retry-After": []string{value}}}
+	}
+	tests := []struct {
+		name  string
+		retry int
+		resp  *http.Response
+		want  time.Duration
+	}{
+		{"first", 1, nil, time.Second},
+		{"second", 2, nil, 2 * time.Second},
+		{"third", 3, nil, 4 * time.Second},
+		{"capped", 10, nil, 5 * time.Second},
+		{"retryAfter", 1, retryAfter("3"), 3 * time.Second},
+		{"retryAfterShorter", 3, retryAfter("1"), 4 * time.Second},
+		{"retryAfterCapped", 1, retryAfter("60"), 5 * time.Second},
+		{"retryAfterInvalid", 1, retryAfter("soon"), time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.backoff(tt.retry, tt.resp); got != tt.want {
+				t.Errorf("%v RetryPolicy.backoff() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	tests := []struct {
+		name   string
+		value  string
+		wantOk bool
+	}{
+		{"empty", "", false},
+		{"seconds", "120", true},
+		{"date", date, true},
+		{"invalid", "soon", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := parseRetryAfter(tt.value); ok != tt.wantOk {
+				t.Errorf("%v parseRetryAfter() ok = %v, want %v", tt.name, ok, tt.wantOk)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package meteor
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -10,9 +11,10 @@ import (
 	"net/url"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
-	goquery "github.com/google/go-querystring/query"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
@@ -23,7 +25,7 @@ const (
 
 // Doer executes http requests.  It is implemented by *http.Client.
 // You can wrap *http.Client with layers of Doers to form a stack
-// of client-side middleware.
+// of client-side middleware (see Middleware and Service.Use).
 type Doer interface {
 	Do(req *http.Request) (*http.Response, error)
 }
@@ -41,16 +43,60 @@ type Service struct {
 	method string
 	// raw url string for requests
 	rawURL string
+	// RFC 6570 path template and its parameters, expanded by Request()
+	pathTemplate string
+	params       map[string]interface{}
 	// stores key-values pairs to add to request's Headers
 	header http.Header
-	// url tagged query structs
+	// query sources in the order they were added: url tagged query structs,
+	// maps and parameters added with Query and SetQuery
 	queryStructs []interface{}
+	// encode the query in the order it was added, or without escaping
+	queryOrdered bool
+	queryRaw     bool
 	// body provider
 	bodyProvider BodyProvider
 	// responder
 	responder Responder
-	// async manager
-	async *async
+	// responder factory creating a fresh responder for every call
+	responderFunc ResponderFunc
+	// decoders of a negotiating responder, listed in the Accept header
+	registry *DecoderRegistry
+	// responder of the last Do call, read by GetResponder and GetSuccess
+	lastMu sync.Mutex
+	last   Responder
+	// context used for requests built by the Service
+	ctx context.Context
+	// retry policy
+	retryPolicy *RetryPolicy
+	// hedge policy
+	hedgePolicy *HedgePolicy
+	// circuit breaker shared with children
+	breaker *CircuitBreaker
+	// http cache shared with children
+	cache *Cache
+	// coalescer of identical requests shared with children
+	coalescer *Coalescer
+	// rate limiter shared with children and the key of its bucket
+	rateLimiter  *RateLimiter
+	rateLimitKey string
+	// client-side middleware wrapping the httpClient
+	middleware []Middleware
+	// structured logger, levels and redaction of logged values
+	logger    Logger
+	logLevels *LogLevels
+	redactor  *Redactor
+	// return a nil error instead of an *APIError for failure responses
+	noAPIError bool
+	// number of workers, per request timeout and completion used by DoAsync
+	asyncWorkers    int
+	asyncTimeout    time.Duration
+	asyncCompletion Completion
+	// paginator of Pages and its maximum number of pages
+	paginator Paginator
+	maxPages  int
+	// errors recorded by builders, returned by Request()
+	errs []error
 }
 
 // New returns a new Service with an http DefaultClient.
@@ -82,14 +128,46 @@ func (s *Service) New() *Service {
 	for k, v := range s.header {
 		headerCopy[k] = v
 	}
+	var paramsCopy map[string]interface{}
+	if s.params != nil {
+		paramsCopy = make(map[string]interface{}, len(s.params))
+		for k, v := range s.params {
+			paramsCopy[k] = v
+		}
+	}
 	return &Service{
-		httpClient:   s.httpClient,
-		method:       s.method,
-		rawURL:       s.rawURL,
-		header:       headerCopy,
-		queryStructs: append([]interface{}{}, s.queryStructs...),
-		bodyProvider: s.bodyProvider,
-		responder:    s.responder,
+		httpClient:      s.httpClient,
+		method:          s.method,
+		rawURL:          s.rawURL,
+		pathTemplate:    s.pathTemplate,
+		params:          paramsCopy,
+		header:          headerCopy,
+		queryStructs:    append([]interface{}{}, s.queryStructs...),
+		queryOrdered:    s.queryOrdered,
+		queryRaw:        s.queryRaw,
+		bodyProvider:    s.bodyProvider,
+		responder:       s.responder,
+		responderFunc:   s.responderFunc,
+		registry:        s.registry,
+		ctx:             s.ctx,
+		retryPolicy:     s.retryPolicy,
+		hedgePolicy:     s.hedgePolicy,
+		breaker:         s.breaker,
+		cache:           s.cache,
+		coalescer:       s.coalescer,
+		rateLimiter:     s.rateLimiter,
+		rateLimitKey:    s.rateLimitKey,
+		middleware:      append([]Middleware{}, s.middleware...),
+		logger:          s.logger,
+		logLevels:       s.logLevels,
+		redactor:        s.redactor,
+		noAPIError:      s.noAPIError,
+		asyncWorkers:    s.asyncWorkers,
+		asyncTimeout:    s.asyncTimeout,
+		asyncCompletion: s.asyncCompletion,
+		paginator:       s.paginator,
+		maxPages:        s.maxPages,
+		errs:            append([]error(nil), s.errs...),
 	}
 }
 
@@ -98,14 +176,54 @@ func (s *Service) Reset() *Service {
 	s.httpClient = GetDefaultClient()
 	s.method = "GET"
 	s.rawURL = ""
+	s.pathTemplate = ""
+	s.params = nil
 	s.bodyProvider = nil
 	s.header = make(http.Header)
 	s.queryStructs = make([]interface{}, 0)
+	s.queryOrdered = false
+	s.queryRaw = false
 	s.responder = GenericResponder()
+	s.responderFunc = nil
+	s.registry = nil
+	s.setLast(nil)
+	s.ctx = nil
+	s.retryPolicy = nil
+	s.hedgePolicy = nil
+	s.breaker = nil
+	s.cache = nil
+	s.coalescer = nil
+	s.rateLimiter = nil
+	s.rateLimitKey = ""
+	s.middleware = nil
+	s.logger = nil
+	s.logLevels = nil
+	s.redactor = nil
+	s.noAPIError = false
+	s.asyncWorkers = 0
+	s.asyncTimeout = 0
+	s.asyncCompletion = WaitAll()
+	s.paginator = nil
+	s.maxPages = 0
+	s.errs = nil
 
 	return s
 }
 
+// Err returns the errors recorded by builders, such as a Path that cannot be
+// parsed, joined with errors.Join, or nil if there are none. Request() and
+// Do() return the same errors, so a misconfigured Service fails before
+// sending anything. Errors are copied into children created with New()
+// and cleared by Reset().
+func (s *Service) Err() error {
+	return errors.Join(s.errs...)
+}
+
+// addErr records the error of a builder.
+func (s *Service) addErr(err error) {
+	s.errs = append(s.errs, err)
+}
+
 // Http Client
 
 // Service sets the http Service used to do requests. If a nil client is given,
@@ -128,6 +246,192 @@ func (s *Service) Doer(doer Doer) *Service {
 	return s
 }
 
+// Use appends middleware wrapping the Service's Doer. Middleware is applied
+// in order, so the first one sees the request first. Middleware is copied
+// into children created with New().
+func (s *Service) Use(middleware ...Middleware) *Service {
+	for _, m := range middleware {
+		if m != nil {
+			s.middleware = append(s.middleware, m)
+		}
+	}
+	return s
+}
+
+// Logging
+
+// Logger sets the Logger used to log request and response events, e.g. a
+// *slog.Logger. Sensitive query parameters and headers are redacted (see
+// Redactor()). If a nil logger is given, logging is disabled.
+func (s *Service) Logger(logger Logger) *Service {
+	s.logger = logger
+	return s
+}
+
+// LogLevels sets the levels of the logged request, response and error events.
+// Services log with DefaultLogLevels otherwise.
+func (s *Service) LogLevels(levels LogLevels) *Service {
+	s.logLevels = &levels
+	return s
+}
+
+// getLogLevels gets the Service's LogLevels or the default ones.
+func (s *Service) getLogLevels() LogLevels {
+	if s.logLevels == nil {
+		return DefaultLogLevels
+	}
+	return *s.logLevels
+}
+
+// Redactor sets the Redactor used to hide sensitive query parameters and
+// headers. If a nil redactor is given, NewRedactor() defaults are used.
+func (s *Service) Redactor(redactor *Redactor) *Service {
+	s.redactor = redactor
+	return s
+}
+
+// getRedactor gets the Service's Redactor or the default one.
+func (s *Service) getRedactor() *Redactor {
+	if s.redactor == nil {
+		return defaultRedactor
+	}
+	return s.redactor
+}
+
+// Retry
+
+// Retry sets the RetryPolicy used to resend failed requests. The policy is
+// inherited by children created with New(). If a nil policy is given,
+// requests are not retried.
+func (s *Service) Retry(policy *RetryPolicy) *Service {
+	s.retryPolicy = policy
+	return s
+}
+
+// CircuitBreaker
+
+// CircuitBreaker sets the CircuitBreaker failing requests fast with
+// ErrCircuitOpen while their circuit is open. Children created with New()
+// share the breaker, and its state. A request counts once however many
+// times it is retried. If a nil breaker is given, circuits are not used.
+func (s *Service) CircuitBreaker(breaker *CircuitBreaker) *Service {
+	s.breaker = breaker
+	return s
+}
+
+// Cache
+
+// Cache sets the Cache serving GET and HEAD requests. Fresh cached responses
+// are served without sending the request, so they are not rate limited nor
+// retried. Children created with New() share the cache. If a nil cache is
+// given, responses are not cached.
+func (s *Service) Cache(cache *Cache) *Service {
+	s.cache = cache
+	return s
+}
+
+// Coalesce
+
+// Coalesce sets the Coalescer sharing one upstream call between identical
+// in-flight GET and HEAD requests. Each caller decodes its own copy of the
+// response with its own Responder. Children created with New() share the
+// coalescer. If a nil coalescer is given, requests are not coalesced.
+func (s *Service) Coalesce(coalescer *Coalescer) *Service {
+	s.coalescer = coalescer
+	return s
+}
+
+// RateLimit
+
+// RateLimit sets the RateLimiter every request waits on before it is sent,
+// including retries and requests sent by DoAsync. Requests use the bucket of
+// key, such as a credential name, or of their host if no key is given.
+// Children created with New() share the limiter. If a nil limiter is given,
+// requests are not limited.
+func (s *Service) RateLimit(limiter *RateLimiter, key ...string) *Service {
+	s.rateLimiter = limiter
+	s.rateLimitKey = ""
+	if len(key) > 0 {
+		s.rateLimitKey = key[0]
+	}
+	return s
+}
+
+// Async
+
+// AsyncWorkers limits the number of requests DoAsync sends at once to n.
+// Requests wait for a free worker in the order they are given. A value of
+// 0 or less sends every request at once.
+func (s *Service) AsyncWorkers(n int) *Service {
+	s.asyncWorkers = n
+	return s
+}
+
+// AsyncTimeout bounds the time each request sent by DoAsync may take,
+// including reading its response. A value of 0 or less means no timeout.
+func (s *Service) AsyncTimeout(d time.Duration) *Service {
+	s.asyncTimeout = d
+	return s
+}
+
+// AsyncCompletion sets when DoAsync is complete, see WaitAll, FirstSuccess,
+// FirstError and Quorum. Requests that are not done when DoAsync completes
+// are cancelled. By default, DoAsync waits for every request.
+func (s *Service) AsyncCompletion(c Completion) *Service {
+	s.asyncCompletion = c
+	return s
+}
+
+// Hedge
+
+// Hedge sets the HedgePolicy used to send a duplicate of slow GET and HEAD
+// requests. Only the first response is passed to the Responder. The policy
+// is inherited by children created with New(). If a nil policy is given,
+// requests are not hedged.
+func (s *Service) Hedge(policy *HedgePolicy) *Service {
+	s.hedgePolicy = policy
+	return s
+}
+
+// Pagination
+
+// Paginate sets the Paginator finding the request of the next page for
+// Pages, such as a LinkPaginator, CursorPaginator, OffsetPaginator or
+// PageNumberPaginator. If a nil paginator is given, Pages only requests the
+// first page.
+func (s *Service) Paginate(paginator Paginator) *Service {
+	s.paginator = paginator
+	return s
+}
+
+// MaxPages limits the number of pages Pages requests to n. A value of 0 or
+// less uses DefaultMaxPages.
+func (s *Service) MaxPages(n int) *Service {
+	s.maxPages = n
+	return s
+}
+
+// Context
+
+// Context sets the context.Context used for requests created by the Service
+// (see Request()). Cancelling the context cancels any in-flight requests,
+// including those started by DoAsync. If a nil context is given, nothing is changed.
+func (s *Service) Context(ctx context.Context) *Service {
+	if ctx == nil {
+		return s
+	}
+	s.ctx = ctx
+	return s
+}
+
+// GetContext gets the Service's context, defaulting to context.Background().
+func (s *Service) GetContext() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
 // Method
 
 // Method sets the Service method and the path to the given pathURL
@@ -247,15 +551,21 @@ func basicAuth(username, password string) string {
 
 // RawBase sets the rawURL. If you intend to extend the url with Path,
 // baseUrl should be specified with a trailing slash, or just use Base.
+// If the rawURL cannot be parsed, the error is recorded (see Err()) and the
+// rawURL is left unmodified.
 func (s *Service) RawBase(rawURL string) *Service {
+	if _, err := url.Parse(rawURL); err != nil {
+		s.addErr(fmt.Errorf("meteor: base: %w", err))
+		return s
+	}
 	s.rawURL = rawURL
 	return s
 }
 
-// Base sets the rawURL.
+// Base sets the rawURL with a trailing slash. If the rawURL cannot be
+// parsed, the error is recorded (see Err()) and the rawURL is left unmodified.
 func (s *Service) Base(rawURL string) *Service {
-	s.rawURL = s.slashIt(rawURL)
-	return s
+	return s.RawBase(s.slashIt(rawURL))
 }
 
 // slashIt adds a trailing slash to the string ensuring there are no double slashes.
@@ -264,47 +574,81 @@ func (s *Service) slashIt(str string) string {
 }
 
 // Path extends the rawURL with the given path by resolving the reference to
-// an absolute URL. If parsing errors occur, the rawURL is left unmodified.
+// an absolute URL. If parsing errors occur, the error is recorded (see Err())
+// and the rawURL is left unmodified.
 func (s *Service) Path(path string) *Service {
 	if path == "" {
 		return s
 	}
 
-	if !strings.HasSuffix(s.rawURL, "/") {
-		s.rawURL += "/"
+	rawURL := s.rawURL
+	if !strings.HasSuffix(rawURL, "/") {
+		rawURL += "/"
 	}
-	baseURL, baseErr := url.Parse(s.rawURL)
-	pathURL, pathErr := url.Parse(path)
-	if baseErr == nil && pathErr == nil {
-		s.rawURL = baseURL.ResolveReference(pathURL).String()
+	baseURL, err := url.Parse(rawURL)
+	if err != nil {
+		s.addErr(fmt.Errorf("meteor: path: %w", err))
 		return s
 	}
+	pathURL, err := url.Parse(path)
+	if err != nil {
+		s.addErr(fmt.Errorf("meteor: path: %w", err))
+		return s
+	}
+	s.rawURL = baseURL.ResolveReference(pathURL).String()
 	return s
 }
 
 // Pathf extends the rawURL with the given path format by resolving the format and
-// the reference to an absolute URL. If parsing errors occur, the rawURL is left unmodified.
+// the reference to an absolute URL. If parsing errors occur, the error is
+// recorded (see Err()) and the rawURL is left unmodified.
 func (s *Service) Pathf(format string, a ...interface{}) *Service {
 	return s.Path(fmt.Sprintf(format, a...))
 }
 
+// PathTemplate sets an RFC 6570 URI template, e.g.
+// "geocode/{lat}/{lon}/forecast/daily/{days}day.json", extending the rawURL
+// like Path when a request is created (see Request()). Placeholders are
+// bound with Param and every value is escaped. Request() returns an
+// ErrUnboundParam for placeholders left unbound.
+func (s *Service) PathTemplate(template string) *Service {
+	s.pathTemplate = template
+	return s
+}
+
+// Param binds the value of the name placeholder of the PathTemplate.
+// Strings, numbers and other scalars are formatted with fmt, slices expand
+// to lists and maps to associative arrays.
+func (s *Service) Param(name string, value interface{}) *Service {
+	if s.params == nil {
+		s.params = make(map[string]interface{})
+	}
+	s.params[name] = value
+	return s
+}
+
 // ResetPath resets the path to a slash.
 func (s *Service) ResetPath() *Service {
 	return s.Path("/")
 }
 
 // Extension adds an extension to the end of the path. If parsing errors
-// occur, the rawURL is left unmodified.
+// occur, the error is recorded (see Err()) and the rawURL is left unmodified.
 func (s *Service) Extension(ext string) *Service {
-	baseURL, baseErr := url.Parse(s.rawURL)
-	if baseErr == nil && ext != "" {
-		rawURL := baseURL.String() + "." + strings.TrimLeft(ext, ".")
-		extURL, extErr := url.Parse(rawURL)
-		if extErr == nil {
-			s.rawURL = baseURL.ResolveReference(extURL).String()
-		}
+	if ext == "" {
+		return s
+	}
+	baseURL, err := url.Parse(s.rawURL)
+	if err != nil {
+		s.addErr(fmt.Errorf("meteor: extension: %w", err))
+		return s
+	}
+	extURL, err := url.Parse(baseURL.String() + "." + strings.TrimLeft(ext, "."))
+	if err != nil {
+		s.addErr(fmt.Errorf("meteor: extension: %w", err))
 		return s
 	}
+	s.rawURL = baseURL.ResolveReference(extURL).String()
 	return s
 }
 
@@ -313,10 +657,50 @@ func (s *Service) Extension(ext string) *Service {
 // new requests (see Request()).
 // The queryStruct argument should be a pointer to a url tagged struct. See
 // https://godoc.org/github.com/google/go-querystring/query for details.
+// url.Values and maps with string keys, such as map[string]string, are
+// encoded with their keys and values.
+// Maps with other keys are recorded as errors (see Err()).
 func (s *Service) QueryStruct(queryStruct interface{}) *Service {
-	if queryStruct != nil && reflect.TypeOf(queryStruct).Kind() != reflect.Map {
-		s.queryStructs = append(s.queryStructs, queryStruct)
+	if queryStruct == nil {
+		return s
+	}
+	if t := reflect.TypeOf(queryStruct); t.Kind() == reflect.Map && t.Key().Kind() != reflect.String {
+		s.addErr(fmt.Errorf("meteor: query: unsupported map key type %v", t.Key()))
+		return s
 	}
+	s.queryStructs = append(s.queryStructs, queryStruct)
+	return s
+}
+
+// Query adds the value to the query parameter key on new requests,
+// after the values already added.
+func (s *Service) Query(key, value string) *Service {
+	s.queryStructs = append(s.queryStructs, queryParam{key: key, value: value})
+	return s
+}
+
+// SetQuery sets the query parameter key to value on new requests,
+// replacing the values added before, including those of the Base URL.
+func (s *Service) SetQuery(key, value string) *Service {
+	s.queryStructs = append(s.queryStructs, queryParam{key: key, value: value, set: true})
+	return s
+}
+
+// OrderedQuery sets whether query parameters are encoded in the order they
+// were added (the Base URL's, then QueryStruct, Query and SetQuery in call
+// order), for APIs that need it. By default they are sorted by key. Fields
+// of a struct keep their declaration order, keys of a map are sorted.
+func (s *Service) OrderedQuery(ordered bool) *Service {
+	s.queryOrdered = ordered
+	return s
+}
+
+// UnescapedQuery sets whether query keys and values are sent without
+// escaping. Values containing "&", "=", "+", "#" or spaces then break the
+// query, so only enable it for APIs that require unescaped values. The query
+// of the Base URL and Path keeps its original encoding.
+func (s *Service) UnescapedQuery(raw bool) *Service {
+	s.queryRaw = raw
 	return s
 }
 
@@ -366,6 +750,20 @@ func (s *Service) PNG() *Service {
 	return s
 }
 
+// XML sets the Service's Content Type to xmlContentType ("application/xml").
+func (s *Service) XML() *Service {
+	s.Set(contentType, xmlContentType)
+
+	return s
+}
+
+// Protobuf sets the Service's Content Type to protobufContentType ("application/x-protobuf").
+func (s *Service) Protobuf() *Service {
+	s.Set(contentType, protobufContentType)
+
+	return s
+}
+
 // Form sets the Service's Content Type to formContentType ("application/x-www-form-urlencoded").
 func (s *Service) Form() *Service {
 	s.Set(contentType, formContentType)
@@ -411,6 +809,27 @@ func (s *Service) BodyJSON(body interface{}) *Service {
 	return s.BodyProvider(jsonBodyProvider{payload: body})
 }
 
+// BodyXML sets the Service's bodyXML. The value pointed to by the bodyXML
+// will be XML encoded as the Body on new requests (see Request()).
+// The bodyXML argument should be a pointer to an XML tagged struct. See
+// https://golang.org/pkg/encoding/xml/#Marshal for details.
+func (s *Service) BodyXML(body interface{}) *Service {
+	if body == nil {
+		return s
+	}
+	return s.BodyProvider(xmlBodyProvider{payload: body})
+}
+
+// BodyProtobuf sets the Service's bodyProtobuf. The message will be protobuf
+// encoded as the Body on new requests (see Request()). See
+// https://pkg.go.dev/google.golang.org/protobuf/proto#Marshal for details.
+func (s *Service) BodyProtobuf(body proto.Message) *Service {
+	if body == nil {
+		return s
+	}
+	return s.BodyProvider(protobufBodyProvider{payload: body})
+}
+
 // BodyForm sets the Service's bodyForm. The value pointed to by the bodyForm
 // will be url encoded as the Body on new requests (see Request()).
 // The bodyForm argument should be a pointer to a url tagged struct. See
@@ -422,71 +841,205 @@ func (s *Service) BodyForm(body interface{}) *Service {
 	return s.BodyProvider(formBodyProvider{payload: body})
 }
 
+// BodyMultipart sets the Service's body to a multipart/form-data body,
+// streamed as it is sent (see MultipartBody). The body argument should be a
+// *MultipartBody, or a pointer to a multipart tagged struct added with
+// MultipartBody.Struct. The Content-Type header carries the boundary.
+func (s *Service) BodyMultipart(body interface{}) *Service {
+	if body == nil {
+		return s
+	}
+	provider, ok := body.(*MultipartBody)
+	if !ok {
+		provider = NewMultipartBody().Struct(body)
+	}
+	return s.BodyProvider(provider)
+}
+
 // Responders
 
-// Responder sets the Service's responder.
+// APIErrors sets whether Do returns the *APIError of responses that are not OK,
+// which is the default. Pass false to return a nil error and only decode the
+// failure value, as Meteor did before APIError was introduced. The setting
+// also applies to the Responders of the Service's calls, such as the one of
+// an AsyncRequest, when their DoResponse is called directly.
+func (s *Service) APIErrors(enabled bool) *Service {
+	s.noAPIError = !enabled
+	return s
+}
+
+// Responder sets the Service's responder. An explicitly set responder
+// replaces any ResponderFunc.
 func (s *Service) Responder(responder Responder) *Service {
 	if responder == nil {
 		return s
 	}
+	return s.setResponder(responder)
+}
+
+// setResponder sets the Service's responder, replacing any ResponderFunc.
+func (s *Service) setResponder(responder Responder) *Service {
 	s.responder = responder
+	s.responderFunc = nil
+	s.registry = responderRegistry(responder)
+	return s
+}
+
+// ResponderFunc sets the Service's responder factory. When set, every call
+// (Do, DoResponder, AsyncRequest) gets a Responder from the factory, until a
+// responder is set again. If a nil factory is given, the Service's responder
+// is used. The factory is called once to find whether its Responders
+// negotiate the content type, and which Accept header they need.
+func (s *Service) ResponderFunc(factory ResponderFunc) *Service {
+	s.responderFunc = factory
+	s.registry = responderRegistry(s.responder)
+	if factory != nil {
+		if r := factory(); r != nil {
+			s.registry = responderRegistry(r)
+		}
+	}
 	return s
 }
 
+// responderRegistry gets the DecoderRegistry of a negotiating responder.
+func responderRegistry(responder Responder) *DecoderRegistry {
+	if nr, ok := responder.(*negotiatingResponder); ok {
+		return nr.registry
+	}
+	return nil
+}
+
+// callResponder returns the Responder for a single call: one from the
+// Service's ResponderFunc, or a fresh copy of the Service's responder, so
+// calls never share decoding state. Custom Responders that cannot be copied
+// are shared by every call; use a ResponderFunc for them.
+func (s *Service) callResponder() Responder {
+	if s.responderFunc != nil {
+		if r := s.responderFunc(); r != nil {
+			return setAPIErrors(r, s.apiErrorOptions())
+		}
+	}
+	if s.responder == nil {
+		return GenericResponder()
+	}
+	return setAPIErrors(callResponder(s.responder), s.apiErrorOptions())
+}
+
+// apiErrorOptions gets the APIErrors and Redactor settings passed to the
+// Responders of the Service's calls.
+func (s *Service) apiErrorOptions() apiErrorOptions {
+	return apiErrorOptions{redactor: s.redactor, disabled: s.noAPIError}
+}
+
+// setLast records the responder of the last Do call.
+func (s *Service) setLast(responder Responder) {
+	s.lastMu.Lock()
+	defer s.lastMu.Unlock()
+	s.last = responder
+}
+
+// lastResponder gets the responder of the last Do call, or the Service's
+// responder if there was none.
+func (s *Service) lastResponder() Responder {
+	s.lastMu.Lock()
+	defer s.lastMu.Unlock()
+	if s.last != nil {
+		return s.last
+	}
+	if s.responder == nil {
+		return GenericResponder()
+	}
+	return s.responder
+}
+
 // JSONResponder sets the Service's responder to handle a JSON response.
 func (s *Service) JSONResponder(success, failure interface{}) *Service {
-	s.responder = JSONResponder(success, failure)
-	return s
+	return s.setResponder(JSONResponder(success, failure))
 }
 
 // JSONResponder sets the Service's responder to handle a JSON response for successes only.
 func (s *Service) JSONSuccessResponder(success interface{}) *Service {
-	s.responder = JSONSuccessResponder(success)
-	return s
+	return s.setResponder(JSONSuccessResponder(success))
+}
+
+// XMLResponder sets the Service's responder to handle an XML response.
+func (s *Service) XMLResponder(success, failure interface{}, isOKfn ...func(int, *http.Response) bool) *Service {
+	return s.setResponder(XMLResponder(success, failure, isOKfn...))
+}
+
+// XMLSuccessResponder sets the Service's responder to handle an XML response for successes only.
+func (s *Service) XMLSuccessResponder(success interface{}) *Service {
+	return s.setResponder(XMLSuccessResponder(success))
+}
+
+// ProtobufResponder sets the Service's responder to handle a protobuf response.
+// success and failure should be proto.Message values or io.Writers.
+func (s *Service) ProtobufResponder(success, failure interface{}, isOKfn ...func(int, *http.Response) bool) *Service {
+	return s.setResponder(ProtobufResponder(success, failure, isOKfn...))
+}
+
+// ProtobufSuccessResponder sets the Service's responder to handle a protobuf response for successes only.
+func (s *Service) ProtobufSuccessResponder(success interface{}) *Service {
+	return s.setResponder(ProtobufSuccessResponder(success))
+}
+
+// NegotiatingResponder sets the Service's responder to decode responses with
+// the Decoder of their Content-Type, found in the registry, or in
+// DefaultDecoders if registry is nil. Requests without an Accept header are
+// sent with the registered media types (see Request()).
+func (s *Service) NegotiatingResponder(registry *DecoderRegistry, success, failure interface{}, isOKfn ...func(int, *http.Response) bool) *Service {
+	return s.setResponder(NegotiatingResponder(registry, success, failure, isOKfn...))
 }
 
 // BinaryResponder sets the Service's responder to handle a binary response.
 func (s *Service) BinaryResponder(failure interface{}, isOKfn ...func(int, *http.Response) bool) *Service {
-	s.responder = BinaryResponder(failure, isOKfn...)
-	return s
+	return s.setResponder(BinaryResponder(failure, isOKfn...))
 }
 
 // BinarySuccessResponder sets the Service's responder to handle a binary response for success only.
 func (s *Service) BinarySuccessResponder() *Service {
-	s.responder = BinarySuccessResponder()
-	return s
+	return s.setResponder(BinarySuccessResponder())
 }
 
 // BinarySuccessResponder sets the Service's responder to handle a binary response for success only.
 func (s *Service) BinaryFailureResponder(failure interface{}) *Service {
-	s.responder = BinaryFailureResponder(failure)
-	return s
+	return s.setResponder(BinaryFailureResponder(failure))
 }
 
 // BitsetResponder sets the Service's responder to handle a Bitset response.
 func (s *Service) BitsetResponder(failure interface{}, isOKfn ...func(int, *http.Response) bool) *Service {
-	s.responder = BitsetResponder(failure, isOKfn...)
-	return s
+	return s.setResponder(BitsetResponder(failure, isOKfn...))
 }
 
 // BitsetSuccessResponder sets the Service's responder to handle a Bitset response for success only.
 func (s *Service) BitsetSuccessResponder() *Service {
-	s.responder = BitsetSuccessResponder()
-	return s
+	return s.setResponder(BitsetSuccessResponder())
 }
 
 // BitsetSuccessResponder sets the Service's responder to handle a Bitset response for success only.
 func (s *Service) BitsetFailureResponder(failure interface{}) *Service {
-	s.responder = BitsetFailureResponder(failure)
-	return s
+	return s.setResponder(BitsetFailureResponder(failure))
 }
 
 // Requests
 
 // Request returns a new http.Request created with the Service properties.
-// Returns any errors parsing the rawURL, encoding query structs, encoding
+// Returns any errors recorded by builders (see Err()), parsing the rawURL, encoding query structs, encoding
+// the body, or creating the http.Request.
+// The request uses the Service's context (see Context()). If the Service's
+// responder is a NegotiatingResponder, a request without an Accept header
+// accepts the media types of its registry. Close the body of a request that
+// is not sent, as streaming bodies such as MultipartBody hold resources
+// until they are read or closed.
+func (s *Service) Request() (*http.Request, error) {
+	return s.RequestWithContext(s.GetContext())
+}
+
+// RequestWithContext returns a new http.Request created with the Service
+// properties and the given context.
+// Returns any errors recorded by builders (see Err()), parsing the rawURL, encoding query structs, encoding
 // the body, or creating the http.Request.
-func (s *Service) Request() (req *http.Request, err error) {
+func (s *Service) RequestWithContext(ctx context.Context) (req *http.Request, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			// find out exactly what the error was and set err
@@ -504,7 +1057,11 @@ func (s *Service) Request() (req *http.Request, err error) {
 		}
 	}()
 
-	reqURL, err := url.Parse(s.rawURL)
+	if err = s.Err(); err != nil {
+		return nil, err
+	}
+
+	reqURL, err := s.url()
 	if err != nil {
 		return nil, err
 	}
@@ -522,56 +1079,75 @@ func (s *Service) Request() (req *http.Request, err error) {
 		}
 	}
 
-	//ctx, cancel := context.WithCancel(context.TODO())
-	//timer := time.AfterFunc(5*time.Second, func() {
-	//	cancel()
-	//})
-
-	fmt.Printf("URL: %v\n", reqURL.String())
-	req, err = http.NewRequest(s.method, reqURL.String(), body)
+	req, err = http.NewRequestWithContext(ctx, s.method, reqURL.String(), body)
 	if err != nil {
+		if closer, ok := body.(io.Closer); ok {
+			closer.Close()
+		}
 		return nil, err
 	}
 	addHeaders(req, s.header)
+	// a negotiating responder accepts the media types of its decoders
+	if s.registry != nil && req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", s.registry.Accept())
+	}
+	if body != nil && req.GetBody == nil {
+		req.GetBody = getBody(s.bodyProvider, body)
+	}
 
-	//req = req.WithContext(ctx)
 	return req, err
 }
 
 // AsyncRequest returns a new AsyncRequest created with the Service properties.
 // Returns any errors parsing the rawURL, encoding query structs, encoding
 // the body, or creating the AsyncRequest.
+// If responder is nil, a fresh Responder from the Service's ResponderFunc or
+// the Service's responder is used.
 func (s *Service) AsyncRequest(responder Responder) *AsyncRequest {
 	if responder == nil {
-		responder = s.GetResponder()
+		responder = s.callResponder()
 	}
 	return NewAsyncRequestWithResponder(s, responder)
 }
 
-// addQueryStructs parses url tagged query structs using go-querystring to
-// encode them to url.Values and format them onto the url.RawQuery. Any
-// query parsing or encoding errors are returned.
-// TODO Optionally decode the query params.
+// url parses the rawURL extended with the expanded PathTemplate, if any.
+func (s *Service) url() (*url.URL, error) {
+	baseURL, err := url.Parse(s.rawURL)
+	if err != nil || s.pathTemplate == "" {
+		return baseURL, err
+	}
+
+	path, err := expandURITemplate(s.pathTemplate, s.params)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(baseURL.Path, "/") {
+		baseURL.Path += "/"
+		if baseURL.RawPath != "" {
+			baseURL.RawPath += "/"
+		}
+	}
+	pathURL, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	return baseURL.ResolveReference(pathURL), nil
+}
+
+// addQueryStructs encodes the query sources onto the url.RawQuery, after the
+// parameters already on the url. url tagged query structs are encoded with
+// go-querystring. Any query parsing or encoding errors are returned.
 func (s *Service) addQueryStructs(reqURL *url.URL, queryStructs []interface{}) error {
-	urlValues, err := url.ParseQuery(reqURL.RawQuery)
+	params, err := parseQueryParams(reqURL.RawQuery)
 	if err != nil {
 		return err
 	}
-	// encodes query structs into a url.Values map and merges maps
 	for _, queryStruct := range queryStructs {
-		queryValues, err := goquery.Values(queryStruct)
-		if err != nil {
+		if params, err = params.addSource(queryStruct); err != nil {
 			return err
 		}
-		for key, values := range queryValues {
-			for _, value := range values {
-				urlValues.Add(key, value)
-			}
-		}
 	}
-	// url.Values format to a sorted "url encoded" string, e.g. "key=val&foo=bar"
-	reqURL.RawQuery = urlValues.Encode()
-	reqURL.RawQuery, _ = url.QueryUnescape(reqURL.RawQuery)
+	reqURL.RawQuery = params.encode(s.queryOrdered, s.queryRaw)
 
 	return nil
 }
@@ -600,37 +1176,41 @@ func (s *Service) ReceiveSuccess(successV interface{}) (*http.Response, error) {
 // Receive creates a new HTTP request and returns the response. Success
 // responses (2XX) are placed into the value pointed to by successV and
 // other responses are JSON decoded into the value pointed to by failureV.
+// The Service's responder is not modified.
 // Any error creating the request, sending it, or decoding the response is
 // returned.
 // Receive is shorthand for calling Request and Do.
 func (s *Service) Receive(successV, failureV interface{}) (*http.Response, error) {
-	req, err := s.Request()
+	return s.ReceiveContext(s.GetContext(), successV, failureV)
+}
+
+// ReceiveContext is like Receive but sends the request with the given context.
+// ReceiveContext is shorthand for calling RequestWithContext and Do.
+func (s *Service) ReceiveContext(ctx context.Context, successV, failureV interface{}) (*http.Response, error) {
+	req, err := s.RequestWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := s.JSONResponder(successV, failureV).Do(req)
-
-	// Assign the response values to the params
-	//successV = s.responder.GetSuccess()
-	//failureV = s.responder.GetFailure()
-
-	return resp, err
+	// Decode with a responder for this call only, leaving the Service's
+	// responder untouched.
+	return s.do(req, JSONResponder(successV, failureV))
 }
 
+// GetResponder gets the Responder of the last Do call, or the Service's
+// responder if there was none.
 func (s *Service) GetResponder() Responder {
-	if s.responder == nil {
-		s.responder = GenericResponder()
-	}
-	return s.responder
+	return s.lastResponder()
 }
 
+// GetSuccess gets the success value of the last Do call.
 func (s *Service) GetSuccess() interface{} {
-	return s.responder.GetSuccess()
+	return s.lastResponder().GetSuccess()
 }
 
+// GetFailure gets the failure value of the last Do call.
 func (s *Service) GetFailure() interface{} {
-	return s.responder.GetFailure()
+	return s.lastResponder().GetFailure()
 }
 
 // Sending
@@ -638,23 +1218,120 @@ func (s *Service) GetFailure() interface{} {
 // Do sends an HTTP request and returns the response. After the receiving the response,
 // this function calls the appropriate Responder and return a raw Response and error.
 // request is an optional parameter and Do will only accept one request param, even though
-// it is a vardiac parameter.
+// it is a vardiac parameter. A given request is sent with its own context; otherwise
+// the request is created with the Service's context.
+// Every call decodes into fresh values with its own Responder (see
+// ResponderFunc), which GetResponder, GetSuccess and GetFailure return until
+// the next call. The results are then copied into the Service's responder
+// and the values it was given, e.g. by JSONResponder.
 func (s *Service) Do(request ...*http.Request) (*http.Response, error) {
-	var req *http.Request
 	if len(request) == 0 || (len(request) == 1 && request[0] == nil) {
-		fmt.Println("Request")
-		var err error
-		req, err = s.Request()
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		//} else if len(request) == 1 {
-		req = request[0]
-		//} else {
-		//resps := s.DoAsync(reqs)
+		return s.DoContext(s.GetContext())
+	}
+	return s.doFill(request[0])
+}
+
+// DoContext is like Do but sends the request with the given context. If a request
+// is given, a shallow copy of it bound to ctx is sent.
+func (s *Service) DoContext(ctx context.Context, request ...*http.Request) (*http.Response, error) {
+	req, err := s.contextRequest(ctx, request...)
+	if err != nil {
+		return nil, err
 	}
-	resp, err := s.httpClient.Do(req)
+	return s.doFill(req)
+}
+
+// DoResponder is like DoContext but returns the Responder of the call, which
+// holds the response and the decoded values. Each call gets its own
+// Responder, so it is safe for concurrent use.
+func (s *Service) DoResponder(ctx context.Context, request ...*http.Request) (Responder, error) {
+	req, err := s.contextRequest(ctx, request...)
+	if err != nil {
+		return nil, err
+	}
+	responder, _, err := s.doResponder(req)
+	return responder, err
+}
+
+// doResponder sends the request with a Responder of its own, recorded as the
+// responder of the last call.
+func (s *Service) doResponder(req *http.Request) (Responder, *http.Response, error) {
+	responder := s.callResponder()
+	resp, err := s.do(req, responder)
+	s.setLast(responder)
+	return responder, resp, err
+}
+
+// doFill is like doResponder but also records the results of the call into
+// the Service's responder, as a synchronous Do did before every call got a
+// Responder of its own.
+func (s *Service) doFill(req *http.Request) (*http.Response, error) {
+	configured, factory := s.responder, s.responderFunc != nil
+	responder, resp, err := s.doResponder(req)
+	if !factory && configured != nil {
+		fillResponder(configured, responder)
+	}
+	return resp, err
+}
+
+// contextRequest returns the given request bound to ctx or creates a new one.
+func (s *Service) contextRequest(ctx context.Context, request ...*http.Request) (*http.Request, error) {
+	if len(request) == 0 || (len(request) == 1 && request[0] == nil) {
+		return s.RequestWithContext(ctx)
+	}
+	return request[0].WithContext(ctx), nil
+}
+
+// doer composes the Service's middleware around the httpClient. From the
+// outermost, requests go through the cache, the coalescer, the circuit
+// breaker, retries, hedging and the rate limiter, so every attempt and
+// hedge goes through the middleware and waits on the rate limiter.
+func (s *Service) doer() Doer {
+	doer := s.streamDoer()
+	if s.coalescer != nil {
+		doer = s.coalescer.Middleware()(doer)
+	}
+	if s.cache != nil {
+		doer = s.cache.Middleware()(doer)
+	}
+	return doer
+}
+
+// streamDoer is like doer but without the cache and the coalescer, which read
+// whole bodies, for responses streamed for as long as they are open.
+func (s *Service) streamDoer() Doer {
+	doer := Chain(s.httpClient, s.middleware...)
+	if s.rateLimiter != nil {
+		doer = s.rateLimiter.middleware(s.rateLimitKey)(doer)
+	}
+	if s.hedgePolicy != nil {
+		doer = hedgeDoer{doer: doer, policy: s.hedgePolicy}
+	}
+	if s.retryPolicy != nil {
+		doer = retryDoer{doer: doer, policy: s.retryPolicy}
+	}
+	if s.breaker != nil {
+		doer = s.breaker.Middleware()(doer)
+	}
+	return doer
+}
+
+// do sends the request, retrying it according to the Service's RetryPolicy,
+// and calls the given Responder. Responses of streaming Responders skip the
+// cache and the coalescer. do does not modify the Service.
+func (s *Service) do(req *http.Request, responder Responder) (*http.Response, error) {
+	if _, ok := responder.(streamer); ok {
+		return s.doWith(s.streamDoer(), req, responder)
+	}
+	return s.doWith(s.doer(), req, responder)
+}
+
+// doWith is like do but sends the request with the given Doer.
+func (s *Service) doWith(doer Doer, req *http.Request, responder Responder) (*http.Response, error) {
+	s.logRequest(req)
+	start := time.Now()
+	resp, err := doer.Do(req)
+	s.logResponse(req, resp, err, time.Since(start))
 	if err != nil {
 		return resp, err
 	}
@@ -667,19 +1344,58 @@ func (s *Service) Do(request ...*http.Request) (*http.Response, error) {
 	}()
 
 	// Do correct Response
-	return s.responder.Respond(req, resp, err).DoResponse()
+	setAPIErrors(responder, s.apiErrorOptions())
+	resp, err = responder.Respond(req, resp, err).DoResponse()
+	if s.noAPIError && IsAPIError(err) {
+		return resp, nil
+	}
+	return resp, err
 }
 
-// DoAsync performs the requests in an asychronous pattern.
+// DoAsync performs the requests in an asychronous pattern. The responses are
+// returned in the order of reqs, see AsyncWorkers and AsyncTimeout.
 func (s *Service) DoAsync(reqs []AsyncDoer) []*AsyncResponse {
-	var responses []interface{}
-	s.async = NewAsync(s, reqs)
-	responses = s.async.Do()
+	return s.DoAsyncContext(s.GetContext(), reqs)
+}
+
+// DoAsyncContext performs the requests in an asychronous pattern. Cancelling
+// ctx stops every pending job and returns the responses received so far.
+func (s *Service) DoAsyncContext(ctx context.Context, reqs []AsyncDoer) []*AsyncResponse {
+	results, _ := s.DoAsyncBatch(ctx, reqs)
+	return results
+}
+
+// DoAsyncBatch is like DoAsyncContext but also returns why the batch
+// stopped, so a completed quorum can be told from a cancellation.
+func (s *Service) DoAsyncBatch(ctx context.Context, reqs []AsyncDoer) ([]*AsyncResponse, StopReason) {
+	a := NewAsyncContext(ctx, s, reqs)
+	responses := a.Do()
 
 	results := make([]*AsyncResponse, 0)
 	for _, resp := range responses {
 		results = append(results, resp.(*AsyncResponse))
 	}
+	return results, a.StoppedBy
+}
+
+// DoAsyncStream performs the requests in an asychronous pattern and sends
+// each response as soon as it is done, in the order they finish. The channel
+// is closed once every request is done or ctx is cancelled. Callers should
+// read the channel until it is closed, or cancel ctx.
+func (s *Service) DoAsyncStream(ctx context.Context, reqs []AsyncDoer) <-chan *AsyncResponse {
+	values := NewAsyncContext(ctx, s, reqs).Stream()
+
+	results := make(chan *AsyncResponse)
+	go func() {
+		defer close(results)
+		for value := range values {
+			select {
+			case results <- value.(*AsyncResponse):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 	return results
 }
 
@@ -0,0 +1,249 @@
+package meteor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	eventStreamContentType = "text/event-stream"
+	lastEventIDHeader      = "Last-Event-ID"
+)
+
+// DefaultEventRetry is the delay before reconnecting to an event stream,
+// until the server sets another with a retry field.
+var DefaultEventRetry = 3 * time.Second
+
+// Event is a Server-Sent Event.
+type Event struct {
+	// ID is the last event ID of the stream, sent as Last-Event-ID when
+	// reconnecting.
+	ID string
+	// Event is the event type, "message" by default.
+	Event string
+	// Data is the data of the event, its data lines joined by newlines.
+	Data string
+	// Retry is the reconnection delay of the stream, as last set by the server.
+	Retry time.Duration
+}
+
+// TypedEvent is an Event with its Data decoded as JSON into a T.
+type TypedEvent[T any] struct {
+	Event
+	Value T
+}
+
+// Events subscribes to the text/event-stream of the Service's request and
+// returns an iterator over its events. When the stream ends or its
+// connection fails, Events reconnects after the retry delay (see
+// DefaultEventRetry), sending the Last-Event-ID header. Errors sending the
+// request, such as an ErrCircuitOpen, or reading the stream, such as a reset
+// connection, are yielded before reconnecting, so the loop may break. Events stops when ctx is cancelled or the loop
+// breaks, and yields an error last if the request cannot be created, or the
+// server answers with a response other than a 200 OK event stream (an
+// *APIError for failure responses). A 204 No Content response ends the
+// subscription.
+//
+// Requests go through the Service's middleware, retries and rate limiter,
+// but not its cache nor its coalescer. Note that the Timeout of an
+// http.Client includes reading the body, so use a Client without one (see
+// Client()) to avoid reconnecting every Timeout.
+//
+//	for ev, err := range svc.New().Get("alerts/stream").Events(ctx) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func (s *Service) Events(ctx context.Context) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		stream := &eventStream{service: s, retry: DefaultEventRetry}
+		for {
+			reconnect, err := stream.connect(ctx, yield)
+			if err != nil && (!yield(Event{}, err) || !reconnect) {
+				return
+			}
+			if !reconnect || !sleepContext(ctx, stream.retry) {
+				return
+			}
+		}
+	}
+}
+
+// EventsOf is like Service.Events but also decodes the Data of each event
+// as JSON into a T. An event that cannot be decoded is yielded with its
+// error, so the loop may skip it or break.
+func EventsOf[T any](ctx context.Context, s *Service) iter.Seq2[TypedEvent[T], error] {
+	return func(yield func(TypedEvent[T], error) bool) {
+		for ev, err := range s.Events(ctx) {
+			typed := TypedEvent[T]{Event: ev}
+			if err == nil {
+				err = json.Unmarshal([]byte(ev.Data), &typed.Value)
+			}
+			if !yield(typed, err) {
+				return
+			}
+		}
+	}
+}
+
+// eventStream is the state of an event stream kept across reconnections.
+type eventStream struct {
+	service *Service
+	lastID  string
+	retry   time.Duration
+}
+
+// connect reads the events of one connection. It reports whether to
+// reconnect, and returns the error ending the connection: the stream ends
+// with it unless the request was sent and failed.
+func (e *eventStream) connect(ctx context.Context, yield func(Event, error) bool) (bool, error) {
+	s := e.service
+	req, err := s.RequestWithContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", eventStreamContentType)
+	req.Header.Set("Cache-Control", "no-cache")
+	if e.lastID != "" {
+		req.Header.Set(lastEventIDHeader, e.lastID)
+	}
+
+	s.logRequest(req)
+	start := time.Now()
+	resp, err := s.streamDoer().Do(req)
+	s.logResponse(req, resp, err, time.Since(start))
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, nil
+		}
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNoContent:
+		return false, nil
+	case !isOk(resp.StatusCode, resp):
+		return false, newAPIError(s.getRedactor(), req, resp, captureErrorBody(resp), nil)
+	case resp.StatusCode != http.StatusOK:
+		return false, fmt.Errorf("meteor: event stream has status %d %s, want %d %s", resp.StatusCode, http.StatusText(resp.StatusCode), http.StatusOK, http.StatusText(http.StatusOK))
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get(contentType)); mediaType != eventStreamContentType {
+		return false, fmt.Errorf("meteor: event stream has Content-Type %q, want %q", resp.Header.Get(contentType), eventStreamContentType)
+	}
+
+	stopped := false
+	err = e.read(resp, func(ev Event) bool {
+		stopped = !yield(ev, nil)
+		return !stopped
+	})
+	if stopped || ctx.Err() != nil {
+		return false, nil
+	}
+	return true, err
+}
+
+// read parses the events of the response body as they arrive, calling
+// dispatch for each until it returns false or the body ends. It returns the
+// error reading the body, e.g. a reset connection or a line longer than
+// maxEventLineBytes.
+func (e *eventStream) read(resp *http.Response, dispatch func(Event) bool) error {
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 4096), maxEventLineBytes)
+	scanner.Split(scanEventLines)
+
+	var eventType string
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			// a blank line dispatches the event
+			if data.Len() > 0 {
+				ev := Event{ID: e.lastID, Event: eventType, Data: strings.TrimSuffix(data.String(), "\n"), Retry: e.retry}
+				if ev.Event == "" {
+					ev.Event = "message"
+				}
+				if !dispatch(ev) {
+					return nil
+				}
+			}
+			eventType = ""
+			data.Reset()
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // comment
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				e.lastID = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(value, 10, 32); err == nil {
+				e.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// maxEventLineBytes is the longest line of an event stream.
+const maxEventLineBytes = 1 << 20
+
+// scanEventLines is a bufio.SplitFunc splitting lines ended by CRLF, LF or CR.
+func scanEventLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		// a CR may be followed by the LF of a CRLF
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		return 0, nil, nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// sleepContext waits for d, and reports whether ctx is still active.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,204 @@
+package meteor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_Events(t *testing.T) {
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/failure":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		case "/json":
+			w.Header().Set(contentType, jsonContentType)
+			w.Write([]byte(`{}`))
+			return
+		case "/accepted":
+			w.Header().Set(contentType, eventStreamContentType)
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte("data: queued\n\n"))
+			return
+		}
+
+		w.Header().Set(contentType, eventStreamContentType+"; charset=utf-8")
+		switch atomic.AddInt32(&connections, 1) {
+		case 1:
+			if r.Header.Get("Accept") != eventStreamContentType {
+				w.WriteHeader(http.StatusNotAcceptable)
+				return
+			}
+			w.Write([]byte(": comment\n" +
+				"retry: 10\n" +
+				"id: 1\n" +
+				"event: alert\n" +
+				"data: first line\n" +
+				"data:second line\n\n" +
+				"data: crlf\r\n\r\n" +
+				"id: 2\rdata: cr\r\r" +
+				"data: unfinished"))
+		case 2:
+			if r.Header.Get(lastEventIDHeader) != "2" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte("id: 3\ndata: resumed\n\n"))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	var events []Event
+	for ev, err := range New().Base(server.URL).Path("stream").Events(context.Background()) {
+		if err != nil {
+			t.Fatalf("Service.Events() error = %v", err)
+		}
+		events = append(events, ev)
+	}
+	retry := 10 * time.Millisecond
+	want := []Event{
+		{ID: "1", Event: "alert", Data: "first line\nsecond line", Retry: retry},
+		{ID: "1", Event: "message", Data: "crlf", Retry: retry},
+		{ID: "2", Event: "message", Data: "cr", Retry: retry},
+		{ID: "3", Event: "message", Data: "resumed", Retry: retry},
+	}
+	if !assert.Equal(t, want, events) {
+		t.Errorf("Service.Events() = %v, want %v", events, want)
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&connections))
+
+	tests := []struct {
+		name       string
+		path       string
+		wantAPIErr bool
+	}{
+		{"failure", "failure", true},
+		{"notEventStream", "json", false},
+		{"notOK", "accepted", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs []error
+			for _, err := range New().Base(server.URL).Path(tt.path).Events(context.Background()) {
+				errs = append(errs, err)
+			}
+			if len(errs) != 1 || errs[0] == nil || IsAPIError(errs[0]) != tt.wantAPIErr {
+				t.Errorf("%v Service.Events() errors = %v, wantAPIErr %v", tt.name, errs, tt.wantAPIErr)
+			}
+		})
+	}
+}
+
+func TestService_Events_transportError(t *testing.T) {
+	defer func(retry time.Duration) { DefaultEventRetry = retry }(DefaultEventRetry)
+	DefaultEventRetry = time.Millisecond
+
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&connections, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	// the first response opens the circuit, then every connection fails
+	// before reaching the server
+	svc := New().Base(server.URL).CircuitBreaker(NewCircuitBreaker(1, time.Minute))
+	svc.New().Do()
+
+	var errs []error
+	for _, err := range svc.New().Events(context.Background()) {
+		errs = append(errs, err)
+		if len(errs) == 2 {
+			break
+		}
+	}
+	if len(errs) != 2 || !errors.Is(errs[0], ErrCircuitOpen) || !errors.Is(errs[1], ErrCircuitOpen) {
+		t.Errorf("Service.Events() errors = %v, want %v twice", errs, ErrCircuitOpen)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&connections))
+}
+
+func TestService_Events_readError(t *testing.T) {
+	defer func(retry time.Duration) { DefaultEventRetry = retry }(DefaultEventRetry)
+	DefaultEventRetry = time.Millisecond
+
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(contentType, eventStreamContentType)
+		switch atomic.AddInt32(&connections, 1) {
+		case 1:
+			// the connection fails in the middle of an event
+			w.Header().Set("Content-Length", "100")
+			w.Write([]byte("data: first\n\ndata: cut"))
+		case 2:
+			w.Write([]byte("data: " + strings.Repeat("a", maxEventLineBytes) + "\n\n"))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	var data []string
+	var errs []error
+	for ev, err := range New().Base(server.URL).Events(context.Background()) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		data = append(data, ev.Data)
+	}
+	assert.Equal(t, []string{"first"}, data)
+	if len(errs) != 2 || !errors.Is(errs[0], io.ErrUnexpectedEOF) || !errors.Is(errs[1], bufio.ErrTooLong) {
+		t.Errorf("Service.Events() errors = %v, want %v and %v", errs, io.ErrUnexpectedEOF, bufio.ErrTooLong)
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&connections))
+}
+
+func TestService_Events_cancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(contentType, eventStreamContentType)
+		w.Write([]byte("data: {\"text\": \"sunny\"}\n\ndata: not json\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var values []FakeModel
+	var errs []error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ev, err := range EventsOf[FakeModel](ctx, New().Base(server.URL)) {
+			if err != nil {
+				errs = append(errs, err)
+				cancel()
+				continue
+			}
+			values = append(values, ev.Value)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("EventsOf() did not stop when ctx was cancelled")
+	}
+	assert.Equal(t, []FakeModel{{Text: "sunny"}}, values)
+	assert.Len(t, errs, 1)
+}
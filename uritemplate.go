@@ -0,0 +1,266 @@
+package meteor
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrUnboundParam is returned, wrapped with the name of the placeholder,
+// when a URI template placeholder has no value.
+var ErrUnboundParam = errors.New("meteor: unbound URI template parameter")
+
+// ErrDotSegment is returned, wrapped with the segment, when values expand
+// to a "." or ".." path segment, which would move the URL out of its base
+// path.
+var ErrDotSegment = errors.New("meteor: URI template parameter is a dot-segment")
+
+// uriOperator describes the expansion of an RFC 6570 expression operator.
+type uriOperator struct {
+	first         string
+	sep           string
+	named         bool
+	ifEmpty       string
+	allowReserved bool
+}
+
+// uriOperators are the RFC 6570 operators, by their character.
+var uriOperators = map[byte]uriOperator{
+	0:   {"", ",", false, "", false},
+	'+': {"", ",", false, "", true},
+	'#': {"#", ",", false, "", true},
+	'.': {".", ".", false, "", false},
+	'/': {"/", "/", false, "", false},
+	';': {";", ";", true, "", false},
+	'?': {"?", "&", true, "=", false},
+	'&': {"&", "&", true, "=", false},
+}
+
+// expandURITemplate expands the RFC 6570 (level 4) URI template with params.
+// Unlike RFC 6570, which expands undefined variables to nothing, an
+// ErrUnboundParam is returned for placeholders missing from params.
+func expandURITemplate(template string, params map[string]interface{}) (string, error) {
+	expanded := &strings.Builder{}
+	// the ranges of the expanded URI written by expressions
+	var expressions [][2]int
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			expanded.WriteString(template)
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("meteor: unclosed expression in URI template %q", template)
+		}
+		expanded.WriteString(template[:start])
+		from := expanded.Len()
+		if err := expandURIExpression(expanded, template[start+1:start+end], params); err != nil {
+			return "", err
+		}
+		expressions = append(expressions, [2]int{from, expanded.Len()})
+		template = template[start+end+1:]
+	}
+
+	uri := expanded.String()
+	if err := checkDotSegments(uri, expressions); err != nil {
+		return "", err
+	}
+	return uri, nil
+}
+
+// expandURIExpression expands a single expression, without its braces.
+func expandURIExpression(expanded *strings.Builder, expression string, params map[string]interface{}) error {
+	if expression == "" {
+		return errors.New("meteor: empty expression in URI template")
+	}
+	var opChar byte
+	if _, ok := uriOperators[expression[0]]; ok {
+		opChar, expression = expression[0], expression[1:]
+	}
+	op := uriOperators[opChar]
+
+	first := true
+	for _, spec := range strings.Split(expression, ",") {
+		name, explode, prefix, err := parseURIVarspec(spec)
+		if err != nil {
+			return err
+		}
+		value, ok := params[name]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnboundParam, name)
+		}
+		parts, defined := expandURIValue(name, value, op, explode, prefix)
+		if !defined {
+			continue
+		}
+		if first {
+			expanded.WriteString(op.first)
+			first = false
+		} else {
+			expanded.WriteString(op.sep)
+		}
+		expanded.WriteString(parts)
+	}
+	return nil
+}
+
+// checkDotSegments returns an ErrDotSegment if a "." or ".." segment of the
+// path of the expanded URI was written, even partly, by an expression.
+// Dot-segments of the template itself are left alone.
+func checkDotSegments(uri string, expressions [][2]int) error {
+	path := uri
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	for start := 0; start <= len(path); {
+		end := strings.IndexByte(path[start:], '/')
+		if end < 0 {
+			end = len(path)
+		} else {
+			end += start
+		}
+		if segment := path[start:end]; segment == "." || segment == ".." {
+			for _, expression := range expressions {
+				if expression[0] < end && start < expression[1] {
+					return fmt.Errorf("%w: %q", ErrDotSegment, segment)
+				}
+			}
+		}
+		start = end + 1
+	}
+	return nil
+}
+
+// parseURIVarspec parses a variable specification, e.g. "name", "name*"
+// or "name:3".
+func parseURIVarspec(spec string) (name string, explode bool, prefix int, err error) {
+	name = spec
+	if strings.HasSuffix(name, "*") {
+		return strings.TrimSuffix(name, "*"), true, 0, nil
+	}
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		prefix, err = strconv.Atoi(name[i+1:])
+		if err != nil || prefix <= 0 || prefix > 9999 {
+			return "", false, 0, fmt.Errorf("meteor: invalid prefix in URI template variable %q", spec)
+		}
+		name = name[:i]
+	}
+	if name == "" {
+		return "", false, 0, errors.New("meteor: empty variable name in URI template")
+	}
+	return name, false, prefix, nil
+}
+
+// expandURIValue expands the value of a variable. Strings and other scalars
+// are formatted with fmt, slices are lists and maps are associative arrays.
+// Empty lists and maps are undefined.
+func expandURIValue(name string, value interface{}, op uriOperator, explode bool, prefix int) (string, bool) {
+	if value == nil {
+		return "", false
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		if v.Len() == 0 {
+			return "", false
+		}
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = escapeURIValue(fmt.Sprint(v.Index(i).Interface()), op.allowReserved)
+			if explode && op.named {
+				items[i] = namedURIValue(name, items[i], op)
+			}
+		}
+		sep := ","
+		if explode {
+			sep = op.sep
+		}
+		list := strings.Join(items, sep)
+		if op.named && !explode {
+			return namedURIValue(name, list, op), true
+		}
+		return list, true
+	case reflect.Map:
+		if v.Len() == 0 {
+			return "", false
+		}
+		keys := make([]string, 0, v.Len())
+		pairs := make(map[string]string, v.Len())
+		for _, k := range v.MapKeys() {
+			key := fmt.Sprint(k.Interface())
+			keys = append(keys, key)
+			pairs[key] = fmt.Sprint(v.MapIndex(k).Interface())
+		}
+		sort.Strings(keys)
+		items := make([]string, 0, 2*len(keys))
+		for _, key := range keys {
+			k, val := escapeURIValue(key, op.allowReserved), escapeURIValue(pairs[key], op.allowReserved)
+			if explode {
+				items = append(items, k+"="+val)
+			} else {
+				items = append(items, k, val)
+			}
+		}
+		if explode {
+			return strings.Join(items, op.sep), true
+		}
+		list := strings.Join(items, ",")
+		if op.named {
+			return namedURIValue(name, list, op), true
+		}
+		return list, true
+	}
+
+	s := fmt.Sprint(value)
+	if prefix > 0 {
+		if runes := []rune(s); len(runes) > prefix {
+			s = string(runes[:prefix])
+		}
+	}
+	s = escapeURIValue(s, op.allowReserved)
+	if op.named {
+		return namedURIValue(name, s, op), true
+	}
+	return s, true
+}
+
+// namedURIValue prefixes the value with the name for named operators.
+func namedURIValue(name, value string, op uriOperator) string {
+	if value == "" {
+		return name + op.ifEmpty
+	}
+	return name + "=" + value
+}
+
+// escapeURIValue percent-encodes the value, keeping the unreserved
+// characters and, if allowReserved, the reserved characters and existing
+// percent-encoded triplets.
+func escapeURIValue(value string, allowReserved bool) string {
+	escaped := &strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', strings.IndexByte("-._~", c) >= 0:
+			escaped.WriteByte(c)
+		case allowReserved && strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0:
+			escaped.WriteByte(c)
+		case allowReserved && c == '%' && i+2 < len(value) && isHex(value[i+1]) && isHex(value[i+2]):
+			escaped.WriteByte(c)
+		default:
+			fmt.Fprintf(escaped, "%%%02X", c)
+		}
+	}
+	return escaped.String()
+}
+
+// isHex reports whether c is a hexadecimal digit.
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
@@ -0,0 +1,133 @@
+package meteor
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_expandURITemplate(t *testing.T) {
+	// examples from RFC 6570
+	params := map[string]interface{}{
+		"var":   "value",
+		"hello": "Hello World!",
+		"path":  "/foo/bar",
+		"empty": "",
+		"x":     1024,
+		"y":     768,
+		"list":  []string{"red", "green", "blue"},
+		"keys":  map[string]string{"semi": ";", "dot": ".", "comma": ","},
+		"dots":  "..",
+	}
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"simple", "{var}", "value"},
+		{"escaped", "{hello}", "Hello%20World%21"},
+		{"reserved", "{+path}/here", "/foo/bar/here"},
+		{"fragment", "X{#var}", "X#value"},
+		{"multiple", "map?{x,y}", "map?1024,768"},
+		{"prefix", "{var:3}", "val"},
+		{"label", "X{.var}", "X.value"},
+		{"path", "{/var,x}/here", "/value/1024/here"},
+		{"pathParams", "{;x,y,empty}", ";x=1024;y=768;empty"},
+		{"query", "{?x,y,empty}", "?x=1024&y=768&empty="},
+		{"continuation", "?fixed=yes{&x}", "?fixed=yes&x=1024"},
+		{"list", "{list}", "red,green,blue"},
+		{"listExplode", "{/list*}", "/red/green/blue"},
+		{"listQuery", "{?list*}", "?list=red&list=green&list=blue"},
+		{"keys", "{keys}", "comma,%2C,dot,.,semi,%3B"},
+		{"keysExplode", "{?keys*}", "?comma=%2C&dot=.&semi=%3B"},
+		{"slash", "geocode/{path}", "geocode/%2Ffoo%2Fbar"},
+		{"literalDotSegment", "../{var}/./here", "../value/./here"},
+		{"queryDots", "items{?dots}", "items?dots=.."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandURITemplate(tt.template, params)
+			if err != nil {
+				t.Fatalf("%v expandURITemplate() error = %v", tt.name, err)
+			}
+			if got != tt.want {
+				t.Errorf("%v expandURITemplate() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_expandURITemplate_errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		unbound  bool
+	}{
+		{"unbound", "geocode/{lat}/{lon}", true},
+		{"unclosed", "geocode/{var", false},
+		{"empty", "geocode/{}", false},
+		{"prefix", "{var:x}", false},
+		{"dotSegment", "items/{dot}/detail", false},
+		{"dotDotSegment", "items/{dots}/detail", false},
+		{"reservedDotSegment", "items/{+path}", false},
+		{"pathDotSegment", "items{/dots}", false},
+		{"labelDotSegment", "geocode/{.dot}", false},
+		{"adjacentDotSegment", "x/{dot}{dot}", false},
+		{"partialDotSegment", "x/.{dot}/y", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := expandURITemplate(tt.template, map[string]interface{}{"var": "value", "lat": 1, "dot": ".", "dots": "..", "path": "a/../../b"})
+			if err == nil {
+				t.Fatalf("%v expandURITemplate() error = nil", tt.name)
+			}
+			if got := errors.Is(err, ErrUnboundParam); got != tt.unbound {
+				t.Errorf("%v expandURITemplate() error = %v, want unbound %v", tt.name, err, tt.unbound)
+			}
+		})
+	}
+}
+
+func TestService_PathTemplate(t *testing.T) {
+	base := New().Base("https://api.weather.com/v1/")
+	tests := []struct {
+		name    string
+		s       *Service
+		want    string
+		wantErr error
+	}{
+		{"geocode", base.New().PathTemplate("geocode/{lat}/{lon}/forecast/daily/{days}day.json").Param("lat", 34.06).Param("lon", -84.22).Param("days", 3),
+			"https://api.weather.com/v1/geocode/34.06/-84.22/forecast/daily/3day.json", nil},
+		{"escaped", base.New().PathTemplate("location/{id}").Param("id", "a/b?c"),
+			"https://api.weather.com/v1/location/a%2Fb%3Fc", nil},
+		{"noTrailingSlash", New().RawBase("https://api.weather.com/v1").PathTemplate("location/{id}").Param("id", "30339"),
+			"https://api.weather.com/v1/location/30339", nil},
+		{"query", base.New().PathTemplate("location/{id}{?units}").Param("id", "30339").Param("units", "e").QueryStruct(&struct {
+			Language string `url:"language"`
+		}{"en-US"}),
+			"https://api.weather.com/v1/location/30339?language=en-US&units=e", nil},
+		{"childParams", base.New().PathTemplate("location/{id}").Param("id", "30339").New(),
+			"https://api.weather.com/v1/location/30339", nil},
+		{"unbound", base.New().PathTemplate("geocode/{lat}/{lon}").Param("lat", 34.06), "", ErrUnboundParam},
+		{"dotSegment", base.New().PathTemplate("items/{id}/detail").Param("id", ".."), "", ErrDotSegment},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := tt.s.Request()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("%v Service.Request() error = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := req.URL.String(); got != tt.want {
+				t.Errorf("%v Service.Request() URL = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+
+	parent := base.New().PathTemplate("location/{id}").Param("id", "1")
+	parent.New().Param("id", "2")
+	if req, _ := parent.Request(); req.URL.Path != "/v1/location/1" {
+		t.Errorf("Service.New().Param() modified the parent = %v", req.URL.Path)
+	}
+}